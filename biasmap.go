@@ -0,0 +1,285 @@
+package maze
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BiasMap varies the maze bias across the maze, so that corridors can be
+// twisty in some parts and run straight in others. Generators sample it at
+// the cell they are carving from wherever they would use the maze bias.
+type BiasMap interface {
+	// Bias returns the bias, from 0 to 1, at point p of maze m.
+	// Values outside that range are clamped.
+	Bias(m *Maze, p Point) float64
+}
+
+// SetBiasMap makes Generate take the bias from a map instead of using the
+// same bias everywhere. A nil map restores the bias given to Generate.
+func (m *Maze) SetBiasMap(b BiasMap) {
+	m.biasMap = b
+}
+
+// BiasMap returns the maze's bias map, or nil if it has none.
+func (m *Maze) BiasMap() BiasMap {
+	return m.biasMap
+}
+
+// biasAt returns the bias at point p: the bias map's value, or the maze bias.
+func (m *Maze) biasAt(p Point) float64 {
+	if m.biasMap == nil {
+		return m.bias
+	}
+	return min(max(m.biasMap.Bias(m, p), 0), 1)
+}
+
+// BiasFunc is a bias map given by a function of the position.
+type BiasFunc func(x, y int) float64
+
+// Bias implements BiasMap.
+func (f BiasFunc) Bias(m *Maze, p Point) float64 {
+	return f(p.X, p.Y)
+}
+
+// LinearGradient changes the bias evenly across the maze, from From on the
+// side where the gradient starts to To on the opposite side.
+type LinearGradient struct {
+	From, To float64
+	// Angle is the direction of the gradient in degrees: 0 runs from left to
+	// right, 90 from top to bottom, 180 from right to left.
+	Angle float64
+}
+
+// Bias implements BiasMap.
+func (g LinearGradient) Bias(m *Maze, p Point) float64 {
+	dx, dy := math.Cos(g.Angle*math.Pi/180), math.Sin(g.Angle*math.Pi/180)
+	project := func(x, y int) float64 { return float64(x)*dx + float64(y)*dy }
+
+	// The gradient spans the corners of the maze that are first and last along it.
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range []Point{{0, 0}, {m.width - 1, 0}, {0, m.height - 1}, {m.width - 1, m.height - 1}} {
+		lo, hi = min(lo, project(c.X, c.Y)), max(hi, project(c.X, c.Y))
+	}
+	if hi == lo {
+		return g.From
+	}
+	t := (project(p.X, p.Y) - lo) / (hi - lo)
+	return g.From + t*(g.To-g.From)
+}
+
+// RadialFromDen changes the bias with the distance from the nearest den, from
+// Near at its wall to Far at Radius grid steps away and beyond. A maze without
+// dens measures the distance from its center.
+type RadialFromDen struct {
+	Near, Far float64
+	// Radius is the distance at which the bias reaches Far. Zero uses half
+	// the smaller side of the maze.
+	Radius int
+}
+
+// Bias implements BiasMap.
+func (g RadialFromDen) Bias(m *Maze, p Point) float64 {
+	radius := float64(g.Radius)
+	if radius <= 0 {
+		radius = float64(min(m.width, m.height)) / 2
+	}
+
+	distance := math.Inf(1)
+	if len(m.dens) == 0 {
+		distance = math.Hypot(float64(p.X)-float64(m.width-1)/2, float64(p.Y)-float64(m.height-1)/2)
+	}
+	for _, d := range m.dens {
+		// The distance to the den's bounding rectangle.
+		dx := max(d.X-p.X, 0, p.X-(d.X+d.Width-1))
+		dy := max(d.Y-p.Y, 0, p.Y-(d.Y+d.Height-1))
+		distance = min(distance, math.Hypot(float64(dx), float64(dy)))
+	}
+	t := min(distance/radius, 1)
+	return g.Near + t*(g.Far-g.Near)
+}
+
+// BiasRegion is a rectangle of the maze with a bias of its own.
+type BiasRegion struct {
+	X, Y, Width, Height int
+	Bias                float64
+}
+
+// BiasTable gives each of a list of regions its own bias. Where regions
+// overlap the first one wins, and outside all of them the bias given to
+// Generate applies.
+type BiasTable []BiasRegion
+
+// Bias implements BiasMap.
+func (t BiasTable) Bias(m *Maze, p Point) float64 {
+	for _, r := range t {
+		if p.X >= r.X && p.X < r.X+r.Width && p.Y >= r.Y && p.Y < r.Y+r.Height {
+			return r.Bias
+		}
+	}
+	return m.bias
+}
+
+// BiasImage is a bias map taken from a grayscale image, where black is a bias
+// of 0 and white a bias of 1. Like a mask, it is stretched over the whole grid.
+type BiasImage struct {
+	width  int
+	height int
+	levels []float64
+}
+
+// Bias implements BiasMap.
+func (b *BiasImage) Bias(m *Maze, p Point) float64 {
+	x := p.X * b.width / m.width
+	y := p.Y * b.height / m.height
+	return b.levels[y*b.width+x]
+}
+
+// DecodeBiasImage reads a bias map from a PNG image.
+func DecodeBiasImage(r io.Reader) (*BiasImage, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bias image: %w", err)
+	}
+	return biasFromImage(img)
+}
+
+// biasFromImage converts an image to a bias map by its gray level.
+func biasFromImage(img image.Image) (*BiasImage, error) {
+	bounds := img.Bounds()
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return nil, fmt.Errorf("bias image must not be empty")
+	}
+	b := &BiasImage{width: bounds.Dx(), height: bounds.Dy(), levels: make([]float64, bounds.Dx()*bounds.Dy())}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			b.levels[(y-bounds.Min.Y)*b.width+x-bounds.Min.X] = float64(gray.Y) / 0xffff
+		}
+	}
+	return b, nil
+}
+
+// LoadBiasImage reads a bias map from a PNG file.
+func LoadBiasImage(path string) (*BiasImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeBiasImage(f)
+}
+
+// ParseBiasMap returns the bias map described by spec, which is a kind
+// followed by a colon and its settings:
+//
+//	linear:from=0.2,to=0.9,angle=90
+//	radial:near=0.1,far=0.9,radius=12
+//	regions:1,1,19,19=0.1;21,1,19,19=0.9
+//	image:bias.png
+//
+// Regions are given as x,y,width,height=bias and separated by semicolons.
+func ParseBiasMap(spec string) (BiasMap, error) {
+	kind, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	kind = strings.ToLower(kind)
+	switch kind {
+	case "regions":
+		return parseBiasTable(rest)
+	case "image":
+		if strings.TrimSpace(rest) == "" {
+			return nil, fmt.Errorf("image bias map needs a file name")
+		}
+		return LoadBiasImage(strings.TrimSpace(rest))
+	case "linear", "radial":
+	default:
+		return nil, fmt.Errorf("unknown bias map: %q. use 'linear', 'radial', 'regions', or 'image'", kind)
+	}
+
+	opts, err := parseOptions(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s bias map: %w", kind, err)
+	}
+	var b BiasMap
+	if kind == "linear" {
+		b, err = linearFromOptions(opts)
+	} else {
+		b, err = radialFromOptions(opts)
+	}
+	if err == nil {
+		err = opts.check()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s bias map: %w", kind, err)
+	}
+	return b, nil
+}
+
+// linearFromOptions builds a LinearGradient from the "from", "to" and "angle" options.
+func linearFromOptions(opts options) (BiasMap, error) {
+	var g LinearGradient
+	var err error
+	if g.From, err = opts.float("from", 0); err != nil {
+		return nil, err
+	}
+	if g.To, err = opts.float("to", 1); err != nil {
+		return nil, err
+	}
+	if g.Angle, err = opts.float("angle", 0); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// radialFromOptions builds a RadialFromDen from the "near", "far" and "radius" options.
+func radialFromOptions(opts options) (BiasMap, error) {
+	var g RadialFromDen
+	var err error
+	if g.Near, err = opts.float("near", 0); err != nil {
+		return nil, err
+	}
+	if g.Far, err = opts.float("far", 1); err != nil {
+		return nil, err
+	}
+	if g.Radius, err = opts.int("radius", 0); err != nil {
+		return nil, err
+	}
+	if g.Radius < 0 {
+		return nil, fmt.Errorf("option radius must not be negative")
+	}
+	return g, nil
+}
+
+// parseBiasTable parses semicolon-separated regions given as x,y,width,height=bias.
+func parseBiasTable(spec string) (BiasTable, error) {
+	var t BiasTable
+	for _, region := range strings.Split(spec, ";") {
+		rect, value, ok := strings.Cut(region, "=")
+		fields := strings.Split(rect, ",")
+		if !ok || len(fields) != 4 {
+			return nil, fmt.Errorf("invalid bias region %q: use x,y,width,height=bias", region)
+		}
+		var n [4]int
+		for i, f := range fields {
+			v, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return nil, fmt.Errorf("invalid bias region %q: %q is not an integer", region, f)
+			}
+			n[i] = v
+		}
+		if n[2] <= 0 || n[3] <= 0 {
+			return nil, fmt.Errorf("invalid bias region %q: width and height must be positive", region)
+		}
+		bias, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bias region %q: %q is not a number", region, value)
+		}
+		t = append(t, BiasRegion{X: n[0], Y: n[1], Width: n[2], Height: n[3], Bias: bias})
+	}
+	return t, nil
+}
@@ -0,0 +1,164 @@
+package maze_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// straightShare returns the fraction of carved cells with x in [fromX, toX)
+// that a corridor passes straight through.
+func straightShare(m *maze.Maze, fromX, toX int) float64 {
+	open := func(x, y int) bool {
+		cell, ok := m.Cell(x, y)
+		return ok && cell != maze.Wall
+	}
+	straight, cells := 0, 0
+	for y := 1; y < m.Height()-1; y += 2 {
+		for x := fromX | 1; x < toX && x < m.Width()-1; x += 2 {
+			if !open(x, y) {
+				continue
+			}
+			cells++
+			n, s, w, e := open(x, y-1), open(x, y+1), open(x-1, y), open(x+1, y)
+			if n && s && !w && !e || w && e && !n && !s {
+				straight++
+			}
+		}
+	}
+	return float64(straight) / float64(cells)
+}
+
+func TestBiasFunc(t *testing.T) {
+	m, err := maze.New(61, 41, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	half := m.Width() / 2
+	m.SetBiasMap(maze.BiasFunc(func(x, y int) float64 {
+		if x < half {
+			return 0
+		}
+		return 1
+	}))
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	checkPerfectMaze(t, m)
+	if twisty, straight := straightShare(m, 0, half), straightShare(m, half, m.Width()); twisty >= straight {
+		t.Errorf("Expected straighter corridors on the right, got %.2f on the left and %.2f on the right", twisty, straight)
+	}
+
+	// Removing the map restores the bias given to Generate.
+	m.SetBiasMap(nil)
+	if m.BiasMap() != nil {
+		t.Error("Expected no bias map after removing it")
+	}
+}
+
+func TestBiasPresets(t *testing.T) {
+	m, err := maze.New(41, 21, 9, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.3); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+
+	linear := maze.LinearGradient{From: 0.2, To: 0.8}
+	if b := linear.Bias(m, maze.Point{X: 0, Y: 7}); !near(b, 0.2) {
+		t.Errorf("Expected 0.2 on the left edge, got %v", b)
+	}
+	if b := linear.Bias(m, maze.Point{X: 40, Y: 7}); !near(b, 0.8) {
+		t.Errorf("Expected 0.8 on the right edge, got %v", b)
+	}
+	if b := linear.Bias(m, maze.Point{X: 20, Y: 0}); !near(b, 0.5) {
+		t.Errorf("Expected 0.5 in the middle, got %v", b)
+	}
+	down := maze.LinearGradient{From: 0, To: 1, Angle: 90}
+	if b := down.Bias(m, maze.Point{X: 3, Y: 20}); !near(b, 1) {
+		t.Errorf("Expected 1 on the bottom edge, got %v", b)
+	}
+
+	den := m.Dens()[0]
+	radial := maze.RadialFromDen{Near: 0.1, Far: 0.9, Radius: 6}
+	if b := radial.Bias(m, maze.Point{X: den.X - 1, Y: den.Y}); !near(b, 0.1+0.8/6) {
+		t.Errorf("Expected the bias to start falling off at the den wall, got %v", b)
+	}
+	if b := radial.Bias(m, maze.Point{X: 1, Y: 1}); !near(b, 0.9) {
+		t.Errorf("Expected the far bias away from the den, got %v", b)
+	}
+
+	table := maze.BiasTable{{X: 0, Y: 0, Width: 10, Height: 10, Bias: 1}, {X: 5, Y: 5, Width: 10, Height: 10, Bias: 0}}
+	if b := table.Bias(m, maze.Point{X: 7, Y: 7}); b != 1 {
+		t.Errorf("Expected the first region to win, got %v", b)
+	}
+	if b := table.Bias(m, maze.Point{X: 12, Y: 12}); b != 0 {
+		t.Errorf("Expected the second region's bias, got %v", b)
+	}
+	if b := table.Bias(m, maze.Point{X: 30, Y: 15}); b != 0.3 {
+		t.Errorf("Expected the maze bias outside all regions, got %v", b)
+	}
+}
+
+func TestBiasImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(1, 0, color.Gray{Y: 0xff})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	b, err := maze.DecodeBiasImage(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	m, err := maze.New(41, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if v := b.Bias(m, maze.Point{X: 5, Y: 5}); v != 0 {
+		t.Errorf("Expected black to be a bias of 0, got %v", v)
+	}
+	if v := b.Bias(m, maze.Point{X: 35, Y: 5}); v != 1 {
+		t.Errorf("Expected white to be a bias of 1, got %v", v)
+	}
+
+	if _, err := maze.DecodeBiasImage(bytes.NewReader([]byte("not a png"))); err == nil {
+		t.Error("Expected error for an invalid image, but got nil")
+	}
+}
+
+func TestParseBiasMap(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected maze.BiasMap
+	}{
+		{"linear:from=0.2,to=0.9,angle=90", maze.LinearGradient{From: 0.2, To: 0.9, Angle: 90}},
+		{"Linear", maze.LinearGradient{To: 1}},
+		{"radial:near=0.1,far=0.9,radius=12", maze.RadialFromDen{Near: 0.1, Far: 0.9, Radius: 12}},
+		{"regions:1,1,19,19=0.1;21,1,19,19=0.9", maze.BiasTable{{X: 1, Y: 1, Width: 19, Height: 19, Bias: 0.1}, {X: 21, Y: 1, Width: 19, Height: 19, Bias: 0.9}}},
+	}
+	for _, tc := range testCases {
+		b, err := maze.ParseBiasMap(tc.spec)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, but got %v", tc.spec, err)
+		}
+		if !reflect.DeepEqual(b, tc.expected) {
+			t.Errorf("ParseBiasMap(%q) = %+v; want %+v", tc.spec, b, tc.expected)
+		}
+	}
+
+	for _, spec := range []string{"spiral", "linear:from=x", "linear:steep=1", "radial:radius=-1", "regions:1,1,5=0.5", "regions:1,1,0,5=0.5", "image:", "image:missing.png"} {
+		if _, err := maze.ParseBiasMap(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Diagonal names the pair of directions a BinaryTree maze links cells in.
+type Diagonal int
+
+const (
+	// NorthEast links every cell to its northern or eastern neighbor.
+	NorthEast Diagonal = iota
+	// NorthWest links every cell to its northern or western neighbor.
+	NorthWest
+	// SouthEast links every cell to its southern or eastern neighbor.
+	SouthEast
+	// SouthWest links every cell to its southern or western neighbor.
+	SouthWest
+)
+
+// diagonalNames maps the spellings accepted by ParseGenerator to diagonals.
+var diagonalNames = map[string]Diagonal{"ne": NorthEast, "nw": NorthWest, "se": SouthEast, "sw": SouthWest}
+
+// BinaryTree carves the maze with the binary tree algorithm.
+// Every cell is linked to one of its two neighbors towards the chosen diagonal,
+// picked at random. It is very fast and needs no memory, but the texture is
+// strongly skewed: the two sides facing the diagonal are long open corridors,
+// and every path runs towards that corner. The start point and the maze bias are ignored.
+type BinaryTree struct {
+	Diagonal Diagonal
+}
+
+// newBinaryTree builds a BinaryTree from the "diagonal" option (ne, nw, se or sw).
+func newBinaryTree(opts options) (Generator, error) {
+	var g BinaryTree
+	if name, ok := opts["diagonal"]; ok {
+		delete(opts, "diagonal")
+		d, ok := diagonalNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid diagonal: %q. use 'ne', 'nw', 'se', or 'sw'", name)
+		}
+		g.Diagonal = d
+	}
+	return g, opts.check()
+}
+
+// Carve implements Generator.
+func (g BinaryTree) Carve(m *Maze, r *rand.Rand, start Point) error {
+	var dirs []Point
+	switch g.Diagonal {
+	case NorthEast:
+		dirs = []Point{{X: 0, Y: -2}, {X: 2, Y: 0}}
+	case NorthWest:
+		dirs = []Point{{X: 0, Y: -2}, {X: -2, Y: 0}}
+	case SouthEast:
+		dirs = []Point{{X: 0, Y: 2}, {X: 2, Y: 0}}
+	case SouthWest:
+		dirs = []Point{{X: 0, Y: 2}, {X: -2, Y: 0}}
+	default:
+		return fmt.Errorf("invalid binary tree diagonal: %d", g.Diagonal)
+	}
+
+	for _, c := range m.generationCells() {
+		m.grid[c.Y][c.X] = Path
+		var options []Point
+		for _, dir := range dirs {
+			if next := (Point{X: c.X + dir.X, Y: c.Y + dir.Y}); m.canCarve(c, next) {
+				options = append(options, next)
+			}
+		}
+		if len(options) > 0 {
+			m.carvePassage(c, m.pick(r, c, Point{}, options))
+		}
+	}
+
+	// Cells whose two directions are both blocked by the den start trees of
+	// their own, so join them to the rest of the maze.
+	m.connectComponents(r)
+	return nil
+}
@@ -0,0 +1,58 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestBinaryTree(t *testing.T) {
+	for _, d := range []maze.Diagonal{maze.NorthEast, maze.NorthWest, maze.SouthEast, maze.SouthWest} {
+		m, err := maze.New(41, 21, 11, 7)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.BinaryTree{Diagonal: d}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(2, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+	}
+
+	checkReproducible(t, maze.BinaryTree{Diagonal: maze.SouthWest})
+}
+
+func TestBinaryTreeCorridors(t *testing.T) {
+	m, err := maze.New(21, 11, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	g, err := maze.ParseGenerator("binarytree:diagonal=SW")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.SetGenerator(g); err != nil {
+		t.Fatalf("Failed to set generator: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	// With a south-west bias the bottom row and the left column are open corridors.
+	for x := 1; x < m.Width()-1; x++ {
+		if cell, _ := m.Cell(x, m.Height()-2); cell == maze.Wall {
+			t.Errorf("Expected the bottom row to be open at x=%d:\n%s", x, gridString(m))
+		}
+	}
+	for y := 1; y < m.Height()-1; y++ {
+		if cell, _ := m.Cell(1, y); cell == maze.Wall {
+			t.Errorf("Expected the left column to be open at y=%d:\n%s", y, gridString(m))
+		}
+	}
+
+	if _, err := maze.ParseGenerator("binarytree:diagonal=up"); err == nil {
+		t.Error("Expected error for an invalid diagonal, but got nil")
+	}
+}
@@ -0,0 +1,143 @@
+package maze
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// SetBraid sets the fraction of dead ends, from 0 to 1, that Generate removes
+// after carving by knocking out one of their walls. Every removed dead end adds
+// a loop, so the maze gets several routes between most points; a fraction of 1
+// gives a fully braided maze without dead ends. The default is 0, a perfect maze.
+func (m *Maze) SetBraid(fraction float64) error {
+	if fraction < 0 || fraction > 1 {
+		return fmt.Errorf("braid fraction must be between 0.0 and 1.0")
+	}
+	m.braid = fraction
+	return nil
+}
+
+// Braid returns the fraction of dead ends that Generate removes.
+func (m *Maze) Braid() float64 {
+	return m.braid
+}
+
+// BraidLoops returns the number of loops that the last Generate added by
+// braiding. For a maze that is perfect before braiding it equals Loops.
+func (m *Maze) BraidLoops() int {
+	return m.braided
+}
+
+// Loops returns the number of independent loops in the maze paths, that is the
+// number of passages that could be walled up without disconnecting any cell.
+// A perfect maze has none. The den is not counted.
+func (m *Maze) Loops() int {
+	cells := m.generationCells()
+	index := make(map[Point]int, len(cells))
+	for i, c := range cells {
+		index[c] = i
+	}
+
+	// The cyclomatic number of the passage graph: edges - nodes + components.
+	nodes, edges := 0, 0
+	sets := newUnionFind(len(cells))
+	components := 0
+	for i, c := range cells {
+		if m.grid[c.Y][c.X] == Wall {
+			continue
+		}
+		nodes++
+		components++
+		for _, next := range []Point{m.step(c, Point{X: 2}), m.step(c, Point{Y: 2})} {
+			j, ok := index[next]
+			if !ok || m.grid[next.Y][next.X] == Wall {
+				continue
+			}
+			if wall := m.wallBetween(c, next); m.grid[wall.Y][wall.X] != Wall {
+				edges++
+				if sets.union(i, j) {
+					components--
+				}
+			}
+		}
+	}
+	return edges - nodes + components
+}
+
+// braidMaze removes the maze's braid fraction of dead ends. Each chosen dead end
+// is opened towards a neighboring dead end if it has one, which removes two at once,
+// and otherwise towards a random neighbor. It returns the number of passages
+// opened, each of which adds a loop.
+func (m *Maze) braidMaze(r *rand.Rand) int {
+	if m.braid <= 0 {
+		return 0
+	}
+	deadEnds := m.deadEnds()
+	r.Shuffle(len(deadEnds), func(i, j int) { deadEnds[i], deadEnds[j] = deadEnds[j], deadEnds[i] })
+	target := int(math.Round(m.braid * float64(len(deadEnds))))
+
+	removed, loops := 0, 0
+	for _, p := range deadEnds {
+		if removed >= target {
+			break
+		}
+		if !m.isDeadEnd(p) {
+			continue // Already opened together with a neighbor.
+		}
+
+		var closed, closedDeadEnds []Point
+		for _, next := range m.carvableNeighbors(p) {
+			wall := m.wallBetween(p, next)
+			if m.grid[next.Y][next.X] == Wall || m.grid[wall.Y][wall.X] != Wall {
+				continue
+			}
+			closed = append(closed, next)
+			if m.isDeadEnd(next) {
+				closedDeadEnds = append(closedDeadEnds, next)
+			}
+		}
+		if len(closedDeadEnds) > 0 {
+			closed = closedDeadEnds
+		}
+		if len(closed) == 0 {
+			continue
+		}
+
+		next := closed[r.Intn(len(closed))]
+		if m.isDeadEnd(next) {
+			removed++
+		}
+		m.carvePassage(p, next)
+		removed++
+		loops++
+	}
+	return loops
+}
+
+// deadEnds returns all carved cells outside the den that have a single exit.
+func (m *Maze) deadEnds() []Point {
+	var deadEnds []Point
+	for _, c := range m.generationCells() {
+		if m.isDeadEnd(c) {
+			deadEnds = append(deadEnds, c)
+		}
+	}
+	return deadEnds
+}
+
+// isDeadEnd reports whether p is a carved cell with exactly one open side.
+func (m *Maze) isDeadEnd(p Point) bool {
+	return m.grid[p.Y][p.X] != Wall && m.exits(p) == 1
+}
+
+// exits returns the number of open sides of the cell at p.
+func (m *Maze) exits(p Point) int {
+	exits := 0
+	for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		if m.grid[p.Y+dir.Y][p.X+dir.X] != Wall {
+			exits++
+		}
+	}
+	return exits
+}
@@ -0,0 +1,114 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// countDeadEnds counts the cells outside the den with exactly one open side.
+func countDeadEnds(m *maze.Maze) int {
+	open := func(x, y int) bool {
+		cell, ok := m.Cell(x, y)
+		return ok && cell != maze.Wall
+	}
+	deadEnds := 0
+	for y := 1; y < m.Height()-1; y += 2 {
+		for x := 1; x < m.Width()-1; x += 2 {
+			if !open(x, y) || m.IsInsideDen(maze.Point{X: x, Y: y}) {
+				continue
+			}
+			exits := 0
+			for _, d := range []maze.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}} {
+				if open(x+d.X, y+d.Y) {
+					exits++
+				}
+			}
+			if exits == 1 {
+				deadEnds++
+			}
+		}
+	}
+	return deadEnds
+}
+
+func TestBraid(t *testing.T) {
+	generate := func(braid float64) *maze.Maze {
+		m, err := maze.New(41, 21, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetBraid(braid); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if err := m.Generate(8, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		return m
+	}
+
+	perfect := generate(0)
+	if perfect.Loops() != 0 {
+		t.Errorf("Expected a perfect maze to have no loops, got %d", perfect.Loops())
+	}
+	deadEnds := countDeadEnds(perfect)
+
+	half := generate(0.5)
+	if got := countDeadEnds(half); got > deadEnds/2+1 {
+		t.Errorf("Expected at most %d dead ends after braiding half, got %d", deadEnds/2+1, got)
+	}
+	if half.Loops() == 0 {
+		t.Error("Expected a partially braided maze to have loops")
+	}
+	for _, m := range []*maze.Maze{perfect, half} {
+		if m.BraidLoops() != m.Loops() {
+			t.Errorf("Expected braiding to report the %d loops it added, got %d", m.Loops(), m.BraidLoops())
+		}
+	}
+
+	full := generate(1)
+	if got := countDeadEnds(full); got != 0 {
+		t.Errorf("Expected no dead ends in a fully braided maze, got %d:\n%s", got, gridString(full))
+	}
+	if full.BraidLoops() != full.Loops() {
+		t.Errorf("Expected braiding to report the %d loops it added, got %d", full.Loops(), full.BraidLoops())
+	}
+	if full.Loops() <= half.Loops() {
+		t.Errorf("Expected more loops with more braiding, got %d and %d", half.Loops(), full.Loops())
+	}
+	if _, found := full.Solve(); !found {
+		t.Error("Braided maze should be solvable, but it is not")
+	}
+}
+
+func TestBraidWithDen(t *testing.T) {
+	m, err := maze.New(41, 21, 11, 7)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetBraid(1); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(8, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	// Braiding must not open extra doors into the den.
+	openings := 0
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			if cell, _ := m.Cell(x, y); cell != maze.Wall && m.IsAdjacentToDen(maze.Point{X: x, Y: y}) {
+				openings++
+			}
+		}
+	}
+	if openings != 1 {
+		t.Errorf("Expected the den to keep a single door, got %d openings", openings)
+	}
+
+	for _, fraction := range []float64{-0.1, 1.1} {
+		if err := m.SetBraid(fraction); err == nil {
+			t.Errorf("Expected error for braid fraction %v, but got nil", fraction)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Cave carves an organic cave instead of a maze of corridors, with a cellular
+// automaton. Every point of the grid outside the dens and the mask starts out
+// as a wall with the chance Fill, and is then smoothed for a number of Steps:
+// an open point becomes a wall when at least Birth of its eight neighbors are
+// walls, and a wall stays one when at least Survive of them are. The default
+// 4-5 rule turns the noise into rounded caverns. Pockets left apart are then
+// joined by tunnels to the cavern that holds the start point.
+// The maze bias and the direction weights are ignored.
+type Cave struct {
+	// Fill is the chance, from 0 to 1, that a point starts out as a wall.
+	// Zero uses 0.45.
+	Fill float64
+	// Steps is the number of smoothing steps. Zero uses 5.
+	Steps int
+	// Birth is the number of wall neighbors that turn an open point into a
+	// wall. Zero uses 5.
+	Birth int
+	// Survive is the number of wall neighbors that keep a wall a wall.
+	// Zero uses 4.
+	Survive int
+}
+
+// newCave builds a Cave from the "fill", "steps", "birth" and "survive" options.
+func newCave(opts options) (Generator, error) {
+	var g Cave
+	var err error
+	if g.Fill, err = opts.float("fill", 0); err != nil {
+		return nil, err
+	}
+	if g.Steps, err = opts.int("steps", 0); err != nil {
+		return nil, err
+	}
+	if g.Birth, err = opts.int("birth", 0); err != nil {
+		return nil, err
+	}
+	if g.Survive, err = opts.int("survive", 0); err != nil {
+		return nil, err
+	}
+	if err := g.check(); err != nil {
+		return nil, err
+	}
+	return g, opts.check()
+}
+
+// check validates the cave settings.
+func (g Cave) check() error {
+	if g.Fill < 0 || g.Fill >= 1 {
+		return fmt.Errorf("cave fill must be at least 0 and below 1")
+	}
+	if g.Steps < 0 {
+		return fmt.Errorf("cave steps must not be negative")
+	}
+	if g.Birth < 0 || g.Birth > 8 || g.Survive < 0 || g.Survive > 8 {
+		return fmt.Errorf("cave birth and survive must be between 0 and 8 neighbors")
+	}
+	return nil
+}
+
+// Carve implements Generator.
+func (g Cave) Carve(m *Maze, r *rand.Rand, start Point) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+	fill, steps, birth, survive := g.Fill, g.Steps, g.Birth, g.Survive
+	if fill == 0 {
+		fill = 0.45
+	}
+	if steps == 0 {
+		steps = 5
+	}
+	if birth == 0 {
+		birth = 5
+	}
+	if survive == 0 {
+		survive = 4
+	}
+
+	// The dens and the walls all around them stay as they are, so that doors
+	// can be opened as usual.
+	open := func(p Point) bool {
+		if p.X <= 0 || p.X >= m.width-1 || p.Y <= 0 || p.Y >= m.height-1 || m.IsMasked(p) {
+			return false
+		}
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if m.IsInsideDen(Point{X: p.X + dx, Y: p.Y + dy}) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for y := 1; y < m.height-1; y++ {
+		for x := 1; x < m.width-1; x++ {
+			if p := (Point{X: x, Y: y}); open(p) && r.Float64() >= fill {
+				m.grid[y][x] = Path
+			}
+		}
+	}
+
+	next := make([][]Cell, m.height)
+	for y := range next {
+		next[y] = make([]Cell, m.width)
+	}
+	for step := 0; step < steps; step++ {
+		for y := 0; y < m.height; y++ {
+			for x := 0; x < m.width; x++ {
+				next[y][x] = m.grid[y][x]
+				if !open(Point{X: x, Y: y}) {
+					continue
+				}
+				walls := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if (dx != 0 || dy != 0) && m.grid[y+dy][x+dx] == Wall {
+							walls++
+						}
+					}
+				}
+				switch {
+				case m.grid[y][x] == Wall && walls < survive:
+					next[y][x] = Path
+				case m.grid[y][x] != Wall && walls >= birth:
+					next[y][x] = Wall
+				}
+			}
+		}
+		for y := range next {
+			copy(m.grid[y], next[y])
+		}
+	}
+
+	// Open the points in front of the middle of each side of the dens, so that
+	// every den has somewhere to put a door.
+	for i := range m.dens {
+		for _, side := range []string{"top", "right", "bottom", "left"} {
+			if _, neighbor, ok, _ := m.sideDoor(i, side); ok {
+				m.grid[neighbor.Y][neighbor.X] = Path
+			}
+		}
+	}
+	m.grid[start.Y][start.X] = Path
+	m.joinPockets(start)
+	return nil
+}
+
+// joinPockets connects every open region of the maze outside the dens to the
+// one that holds the start point, each time by the shortest tunnel from the
+// start's region to another region.
+func (m *Maze) joinPockets(start Point) {
+	for {
+		cells, region := m.openRegion(start)
+		isPocket := func(p Point) bool { return m.grid[p.Y][p.X] == Path && !region[p] }
+		if !m.carvePathFrom(cells, isPocket) {
+			return
+		}
+	}
+}
+
+// openRegion returns the open points outside the dens that can be reached
+// from p, in breadth-first order and as a set.
+func (m *Maze) openRegion(p Point) ([]Point, map[Point]bool) {
+	region := map[Point]bool{p: true}
+	cells := []Point{p}
+	for head := 0; head < len(cells); head++ {
+		current := cells[head]
+		for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			next := Point{X: current.X + dir.X, Y: current.Y + dir.Y}
+			if !region[next] && m.grid[next.Y][next.X] != Wall && !m.IsMasked(next) && !m.IsInsideDen(next) {
+				region[next] = true
+				cells = append(cells, next)
+			}
+		}
+	}
+	return cells, region
+}
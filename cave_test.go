@@ -0,0 +1,115 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// checkCave verifies that every open point outside the dens can be reached
+// from the Start, and that the End is as far from the Start as any point.
+func checkCave(t *testing.T, m *maze.Maze) {
+	t.Helper()
+	distances := pathDistances(m, m.Start())
+	open, farthest := 0, 0
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			p := maze.Point{X: x, Y: y}
+			if cell, _ := m.Cell(x, y); cell == maze.Wall || m.IsInsideDen(p) {
+				continue
+			}
+			open++
+			d, ok := distances[p]
+			if !ok {
+				t.Fatalf("Expected %+v to be reachable from the Start:\n%s", p, gridString(m))
+			}
+			if !m.IsAdjacentToDen(p) {
+				farthest = max(farthest, d)
+			}
+		}
+	}
+	if distances[m.End()] != farthest {
+		t.Errorf("Expected the End %d steps from the Start, got %d", farthest, distances[m.End()])
+	}
+	if share := float64(open) / float64(m.Width()*m.Height()); share < 0.2 || share > 0.8 {
+		t.Errorf("Expected a cave that is neither solid nor empty, got %.2f open", share)
+	}
+	path, found := m.Solve()
+	if !found || len(path)-1 != farthest {
+		t.Errorf("Expected Solve to find the %d step route, got %d (%v)", farthest, len(path)-1, found)
+	}
+}
+
+func TestCave(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		m, err := maze.New(61, 31, 9, 5)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.Cave{}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkCave(t, m)
+		if len(m.Doors()) != 1 {
+			t.Errorf("Expected the den to have a door, got %v", m.Doors())
+		}
+
+		// A cave has open spaces rather than one-cell corridors.
+		rooms := 0
+		for y := 1; y < m.Height()-2; y++ {
+			for x := 1; x < m.Width()-2; x++ {
+				c1, _ := m.Cell(x, y)
+				c2, _ := m.Cell(x+1, y)
+				c3, _ := m.Cell(x, y+1)
+				c4, _ := m.Cell(x+1, y+1)
+				if c1 != maze.Wall && c2 != maze.Wall && c3 != maze.Wall && c4 != maze.Wall {
+					rooms++
+				}
+			}
+		}
+		if rooms < 100 {
+			t.Errorf("Expected open caverns, got only %d open 2x2 blocks", rooms)
+		}
+	}
+
+	checkReproducible(t, maze.Cave{Fill: 0.4, Steps: 4})
+}
+
+func TestCaveGivenPoints(t *testing.T) {
+	m, err := maze.New(41, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	// Fill the cave so that the End is most likely walled in.
+	if err := m.SetGenerator(maze.Cave{Fill: 0.6}); err != nil {
+		t.Fatalf("Failed to set generator: %v", err)
+	}
+	start, end := maze.Point{X: 1, Y: 1}, maze.Point{X: 39, Y: 19}
+	if err := m.Generate(1, &start, &end, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.Start() != start || m.End() != end {
+		t.Errorf("Expected the given Start and End, got %+v and %+v", m.Start(), m.End())
+	}
+	if _, found := m.Solve(); !found {
+		t.Errorf("Expected the given points to be connected:\n%s", gridString(m))
+	}
+}
+
+func TestParseCave(t *testing.T) {
+	g, err := maze.ParseGenerator("cave:fill=0.5,steps=3,birth=6,survive=3")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if expected := (maze.Cave{Fill: 0.5, Steps: 3, Birth: 6, Survive: 3}); g != expected {
+		t.Errorf("Expected %+v, got %+v", expected, g)
+	}
+	for _, spec := range []string{"cave:fill=1", "cave:steps=-1", "cave:birth=9", "cave:rule=45"} {
+		if _, err := maze.ParseGenerator(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+}
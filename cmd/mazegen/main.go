@@ -3,8 +3,11 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,28 +20,315 @@ func main() {
 	height := flag.Int("height", 21, "The height of the maze")
 	denWidth := flag.Int("denWidth", 0, "The width of the central den. Set to 0 for no den.")
 	denHeight := flag.Int("denHeight", 0, "The height of the central den. Set to 0 for no den.")
+	denShape := flag.String("denShape", "", "Shape of the central den (rect, circle, cross, l), where circle:r=N gives a circle a radius of N cells. Defaults to rect.")
+	denRole := flag.String("denRole", "", "Put the maze's start or end in the center of the central den (start, end).")
 	seed := flag.Int64("seed", 0, "Seed for the random number generator. If 0, uses current time.")
-	startX := flag.Int("startX", 0, "The X coordinate for the generation start point. If 0, a random point is chosen.")
-	startY := flag.Int("startY", 0, "The Y coordinate for the generation start point. If 0, a random point is chosen.")
-	endX := flag.Int("endX", 0, "The X coordinate for the maze end point. If 0, a random point is chosen.")
-	endY := flag.Int("endY", 0, "The Y coordinate for the maze end point. If 0, a random point is chosen.")
+	startX := flag.Int("startX", 0, "The X coordinate for the generation start point. If not given, or 0 on a square grid, a random point is chosen.")
+	startY := flag.Int("startY", 0, "The Y coordinate for the generation start point. If not given, or 0 on a square grid, a random point is chosen.")
+	endX := flag.Int("endX", 0, "The X coordinate for the maze end point. If not given, or 0 on a square grid, a random point is chosen.")
+	endY := flag.Int("endY", 0, "The Y coordinate for the maze end point. If not given, or 0 on a square grid, a random point is chosen.")
 	doorX := flag.Int("doorX", 0, "The X coordinate for the den door. If 0, a random door is chosen.")
 	doorY := flag.Int("doorY", 0, "The Y coordinate for the den door. If 0, a random door is chosen.")
-	doorSide := flag.String("doorSide", "", "Side for the den door (top, bottom, left, right). Overrides --doorX/Y.")
+	var doorSides sideList
+	flag.Var(&doorSides, "doorSide", "Side for a den door (top, bottom, left, right). Overrides --doorX/Y. Can be repeated for several doors.")
+	doors := flag.Int("doors", 0, "Number of doors of the central den. If 0, one door per --doorSide, or a single door.")
+	doorPolicy := flag.String("doorPolicy", "random", "Placement of the den doors not given by --doorSide: random, side (one per side) or spread (evenly along the wall around the den).")
+	doorGap := flag.Int("doorGap", 0, "Minimum number of cells between two den doors along the den wall.")
+	entrance := flag.String("entrance", "", "Open the start in the outer wall: a side (top, bottom, left, right) or auto, optionally followed by :offset.")
+	exit := flag.String("exit", "", "Open the end in the outer wall: a side (top, bottom, left, right) or auto, optionally followed by :offset.")
+	placement := flag.String("placement", "", "Strategy for placing the start and end: longest, corners, random:min=N, target:n=N, quadrant:start=Q,end=Q (tl, tr, bl, br) or door. Defaults to longest.")
 	bias := flag.Float64("bias", 0.5, "Bias for straight corridors (0.0 to 1.0). 0 is random, 1 always goes straight if possible.")
+	biasMap := flag.String("biasMap", "", "Vary the bias across the maze: linear:from=F,to=T,angle=A, radial:near=N,far=F,radius=R (from the den), regions:x,y,w,h=B;... or image:file.png.")
+	hbias := flag.Float64("hbias", 0.5, "Weight of carving east or west, relative to --vbias. Higher values give longer horizontal corridors.")
+	vbias := flag.Float64("vbias", 0.5, "Weight of carving north or south, relative to --hbias. Higher values give longer vertical corridors.")
+	turnBias := flag.Float64("turnBias", 1, "Weight of turning while carving, relative to going on. Below 1 straightens corridors, above 1 twists them; 0 counts as 1.")
+	algo := flag.String("algo", "dfs", "Generation algorithm ("+strings.Join(maze.GeneratorNames(), ", ")+"), optionally followed by :key=value options.")
+	maskFile := flag.String("mask", "", "File with a mask that shapes the maze: ASCII art where spaces and dots are out of bounds, or a black-on-white PNG.")
+	braid := flag.Float64("braid", 0, "Fraction of dead ends to remove by adding loops (0.0 to 1.0). 0 gives a perfect maze.")
+	stream := flag.Bool("stream", false, "Stream rows with Eller's algorithm as they are generated. A --height of 0 streams endlessly.")
+	var dens denList
+	flag.Var(&dens, "den", "An additional den as WxH (placed at random) or WxH@X,Y, with an optional /shape after the size and :side for its door. Can be repeated.")
+	solution := flag.String("solution", "", "Search seeds, starting at --seed, for a solution length in steps given as min-max, min- or -max.")
+	deadEnds := flag.String("deadEnds", "", "Search seeds, starting at --seed, for a number of dead ends given as min-max, min- or -max.")
+	junctions := flag.String("junctions", "", "Search seeds, starting at --seed, for a number of junctions given as min-max, min- or -max.")
+	turns := flag.String("turns", "", "Search seeds, starting at --seed, for a number of turns on the solution given as min-max, min- or -max.")
+	attempts := flag.Int("attempts", maze.DefaultAttempts, "Number of seeds to try when searching with --solution, --deadEnds, --junctions or --turns.")
 	solveRatio := flag.Float64("solveRatio", -1.0, "The fraction of the solution path to display (0.0 to 1.0). If not set, maze is not solved.")
+	grid := flag.String("grid", "square", "Shape of the cells: square, hex (pointy-top hexagons), hexflat (flat-top hexagons), polar (concentric rings) or delta (triangles). For hex grids --width and --height count cells and the den has a radius of --denWidth/2. For polar grids --height is the number of rings and the hub has --denWidth/2 rings. For delta grids all sizes count triangles.")
+	svgFile := flag.String("svg", "", "Draw a hex, polar or delta maze as an SVG image into this file. Hex mazes are also printed as text, square ones only as text.")
+	cellSize := flag.Float64("cellSize", 12, "Size of a cell in the SVG image, in pixels.")
+	wrap := flag.String("wrap", "none", "Join opposite borders of a square maze so that passages wrap around: none, horizontal (a cylinder), vertical or torus (both).")
+	depth := flag.Int("depth", 1, "Number of levels of a square maze. Above 1 makes a 3D maze joined by stairs.")
+	startZ := flag.Int("startZ", 0, "The level of the start point of a 3D maze, from 0 at the bottom.")
+	endZ := flag.Int("endZ", -1, "The level of the end point of a 3D maze, from 0 at the bottom. If -1, the highest level.")
 	flag.Parse()
 
+	// Prepare parameters for generation
+	genSeed := *seed
+	if genSeed == 0 {
+		genSeed = time.Now().UnixNano()
+	}
+
+	// Streamed mazes are written row by row and never held in memory.
+	if *stream {
+		if *denWidth > 0 || *denHeight > 0 || len(dens) > 0 {
+			log.Fatalf("Streaming mode does not support a den")
+		}
+		if *hbias != *vbias || *turnBias != 1 {
+			log.Fatalf("Streaming mode does not support direction weights")
+		}
+		// A stream is only ever sized and seeded; every other flag shapes a
+		// maze held in memory, so reject it rather than ignore it.
+		streamFlags := map[string]bool{"width": true, "height": true, "seed": true, "stream": true,
+			"denWidth": true, "denHeight": true, "den": true, "hbias": true, "vbias": true, "turnBias": true}
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "algo" && strings.EqualFold(strings.TrimSpace(*algo), "eller") {
+				return
+			}
+			if !streamFlags[f.Name] {
+				log.Fatalf("Streaming mode does not support --%s", f.Name)
+			}
+		})
+		if err := maze.StreamEller(os.Stdout, *width, *height, genSeed); err != nil {
+			log.Fatalf("Error streaming maze: %v", err)
+		}
+		return
+	}
+
+	switch *grid {
+	case "square":
+		if *depth <= 1 {
+			if isSet("startZ", "endZ") {
+				log.Fatalf("--startZ and --endZ need a --depth above 1")
+			}
+			if isSet("svg", "cellSize") {
+				log.Fatalf("Square mazes are only drawn as text and do not support --svg or --cellSize")
+			}
+			break
+		}
+		// A 3D maze only has a size, its ends, a bias and a generator.
+		rejectFlags("3D mazes", "grid", "width", "height", "depth", "seed", "startX", "startY", "startZ", "endX", "endY", "endZ", "bias", "algo", "solveRatio")
+		m, err := maze.NewMaze3D(*width, *height, *depth)
+		if err != nil {
+			log.Fatalf("Error creating maze: %v", err)
+		}
+		g, err := maze.ParseGenerator(*algo)
+		if err != nil {
+			log.Fatalf("Error setting algorithm: %v", err)
+		}
+		if err := m.SetGenerator(g); err != nil {
+			log.Fatalf("Error setting algorithm: %v", err)
+		}
+		if *endZ < 0 {
+			*endZ = *depth - 1
+		}
+		var start, end *maze.Point3
+		if *startX > 0 && *startY > 0 {
+			start = &maze.Point3{X: *startX, Y: *startY, Z: *startZ}
+		} else if isSet("startZ") {
+			log.Fatalf("--startZ needs --startX and --startY")
+		}
+		if *endX > 0 && *endY > 0 {
+			end = &maze.Point3{X: *endX, Y: *endY, Z: *endZ}
+		} else if isSet("endZ") {
+			log.Fatalf("--endZ needs --endX and --endY")
+		}
+		if err := m.Generate(genSeed, start, end, *bias); err != nil {
+			log.Fatalf("Error generating maze: %v", err)
+		}
+		var solutionPath []maze.Point3
+		if *solveRatio >= 0.0 {
+			if *solveRatio > 1.0 {
+				log.Fatalf("solveRatio must be between 0.0 and 1.0")
+			}
+			if path, found := m.Solve(); found {
+				solutionPath = partialPath(path, *solveRatio)
+			} else {
+				fmt.Println("No solution could be found for the maze.")
+			}
+		}
+		fmt.Print(m.Render(solutionPath))
+		return
+	case "hex", "hexflat", "polar", "delta":
+		// Off the square grid a maze only has a size, a den, its ends, a bias
+		// and a generator, so reject every other flag rather than ignore it.
+		allowed := []string{"grid", "height", "denWidth", "seed", "startX", "startY", "endX", "endY", "bias", "algo", "solveRatio", "svg"}
+		if *svgFile != "" {
+			allowed = append(allowed, "cellSize")
+		}
+		if *grid != "polar" {
+			allowed = append(allowed, "width")
+		}
+		if *grid == "delta" {
+			allowed = append(allowed, "denHeight")
+		}
+		rejectFlags("Mazes on a "+*grid+" grid", allowed...)
+		var gm gridMaze
+		var err error
+		switch *grid {
+		case "hex":
+			gm, err = maze.NewHex(*width, *height, maze.PointyTop, *denWidth/2)
+		case "hexflat":
+			gm, err = maze.NewHex(*width, *height, maze.FlatTop, *denWidth/2)
+		case "polar":
+			gm, err = maze.NewPolar(*height, *denWidth/2)
+		case "delta":
+			gm, err = maze.NewDelta(*width, *height, *denWidth, *denHeight)
+		}
+		if err != nil {
+			log.Fatalf("Error creating maze: %v", err)
+		}
+		// Only hex mazes can be drawn as text.
+		hex, hasText := gm.(*maze.HexMaze)
+		if !hasText && *svgFile == "" {
+			log.Fatalf("Mazes on a %s grid can only be drawn as an image: use --svg", *grid)
+		}
+		g, err := maze.ParseGenerator(*algo)
+		if err != nil {
+			log.Fatalf("Error setting algorithm: %v", err)
+		}
+		if err := gm.SetGenerator(g); err != nil {
+			log.Fatalf("Error setting algorithm: %v", err)
+		}
+		// Cells off the square grid are counted from 0, so a point is
+		// given as soon as either of its coordinates is.
+		var start, end *maze.Point
+		if isSet("startX", "startY") {
+			start = &maze.Point{X: *startX, Y: *startY}
+		}
+		if isSet("endX", "endY") {
+			end = &maze.Point{X: *endX, Y: *endY}
+		}
+		if err := gm.Generate(genSeed, start, end, *bias); err != nil {
+			log.Fatalf("Error generating maze: %v", err)
+		}
+		var solutionPath []maze.Point
+		if *solveRatio >= 0.0 {
+			if *solveRatio > 1.0 {
+				log.Fatalf("solveRatio must be between 0.0 and 1.0")
+			}
+			if path, found := gm.Solve(); found {
+				solutionPath = partialPath(path, *solveRatio)
+			} else {
+				fmt.Println("No solution could be found for the maze.")
+			}
+		}
+		if *svgFile != "" {
+			writeSVG(*svgFile, func(f *os.File) error { return gm.WriteSVG(f, *cellSize, solutionPath) })
+		}
+		if hasText {
+			fmt.Println(hex.Render(solutionPath))
+		}
+		return
+	default:
+		log.Fatalf("Invalid grid: %q. use 'square', 'hex', 'hexflat', 'polar' or 'delta'", *grid)
+	}
+
 	// Create a new maze instance
 	m, err := maze.New(*width, *height, *denWidth, *denHeight)
 	if err != nil {
 		log.Fatalf("Error creating maze: %v", err)
 	}
 
-	// Prepare parameters for generation
-	genSeed := *seed
-	if genSeed == 0 {
-		genSeed = time.Now().UnixNano()
+	if *denShape != "" {
+		d, err := maze.ParseDen(fmt.Sprintf("%dx%d/%s", m.DenWidth(), m.DenHeight(), *denShape))
+		if err != nil {
+			log.Fatalf("Error setting den shape: %v", err)
+		}
+		if err := m.SetDenShape(0, d.Shape); err != nil {
+			log.Fatalf("Error setting den shape: %v", err)
+		}
+	}
+	switch *denRole {
+	case "":
+	case "start", "end":
+		role := maze.StartDen
+		if *denRole == "end" {
+			role = maze.EndDen
+		}
+		if err := m.SetDenRole(0, role, nil); err != nil {
+			log.Fatalf("Error setting den role: %v", err)
+		}
+	default:
+		log.Fatalf("Invalid den role: %q. use 'start' or 'end'", *denRole)
+	}
+	if len(doorSides) > 1 || *doors > 0 || *doorGap > 0 || *doorPolicy != "random" {
+		policy, err := maze.ParseDoorPolicy(*doorPolicy)
+		if err != nil {
+			log.Fatalf("Error setting doors: %v", err)
+		}
+		plan := maze.DoorPlan{DoorCount: *doors, DoorPolicy: policy, DoorGap: *doorGap, DoorSides: doorSides}
+		if err := m.SetDoorPlan(0, plan); err != nil {
+			log.Fatalf("Error setting doors: %v", err)
+		}
+	}
+	for _, d := range dens {
+		if err := m.AddDen(d); err != nil {
+			log.Fatalf("Error adding den: %v", err)
+		}
+	}
+
+	generator, err := maze.ParseGenerator(*algo)
+	if err != nil {
+		log.Fatalf("Error selecting algorithm: %v", err)
+	}
+	if err := m.SetGenerator(generator); err != nil {
+		log.Fatalf("Error selecting algorithm: %v", err)
+	}
+	if *maskFile != "" {
+		mask, err := maze.LoadMask(*maskFile)
+		if err != nil {
+			log.Fatalf("Error loading mask: %v", err)
+		}
+		if err := m.SetMask(mask); err != nil {
+			log.Fatalf("Error applying mask: %v", err)
+		}
+	}
+	if err := m.SetBraid(*braid); err != nil {
+		log.Fatalf("Error setting braid: %v", err)
+	}
+	if *biasMap != "" {
+		b, err := maze.ParseBiasMap(*biasMap)
+		if err != nil {
+			log.Fatalf("Error setting bias map: %v", err)
+		}
+		m.SetBiasMap(b)
+	}
+	weights := maze.DirectionWeights{North: *vbias, South: *vbias, East: *hbias, West: *hbias, Turn: *turnBias}
+	if err := m.SetDirectionWeights(weights); err != nil {
+		log.Fatalf("Error setting direction weights: %v", err)
+	}
+	w, err := maze.ParseWrap(*wrap)
+	if err != nil {
+		log.Fatalf("Error setting wrap: %v", err)
+	}
+	if err := m.SetWrap(w); err != nil {
+		log.Fatalf("Error setting wrap: %v", err)
+	}
+	if *entrance != "" || *exit != "" {
+		openings := make([]*maze.Opening, 2)
+		for i, spec := range []string{*entrance, *exit} {
+			if spec == "" {
+				continue
+			}
+			o, err := maze.ParseOpening(spec)
+			if err != nil {
+				log.Fatalf("Error setting openings: %v", err)
+			}
+			openings[i] = &o
+		}
+		if err := m.SetOpenings(openings[0], openings[1]); err != nil {
+			log.Fatalf("Error setting openings: %v", err)
+		}
+	}
+	if *placement != "" {
+		p, err := maze.ParsePlacement(*placement)
+		if err != nil {
+			log.Fatalf("Error setting placement: %v", err)
+		}
+		if err := m.SetPlacement(p); err != nil {
+			log.Fatalf("Error setting placement: %v", err)
+		}
 	}
 
 	var startPoint *maze.Point
@@ -53,14 +343,52 @@ func main() {
 
 	var doorPoint *maze.Point
 	// doorSide takes precedence over doorX/Y
-	if *doorSide == "" && *doorX > 0 && *doorY > 0 {
+	if len(doorSides) == 0 && *doorX > 0 && *doorY > 0 {
 		doorPoint = &maze.Point{X: *doorX, Y: *doorY}
 	}
+	// Several door sides are part of the den's door plan.
+	doorSide := ""
+	if len(doorSides) == 1 {
+		doorSide = doorSides[0]
+	}
+
+	// Search for a seed that meets the constraints, if any are given.
+	constraints := maze.Constraints{MaxAttempts: *attempts}
+	search := false
+	for _, c := range []struct {
+		spec string
+		r    *maze.Range
+	}{{*solution, &constraints.SolutionLength}, {*deadEnds, &constraints.DeadEnds}, {*junctions, &constraints.Junctions}, {*turns, &constraints.Turns}} {
+		if c.spec == "" {
+			continue
+		}
+		r, err := maze.ParseRange(c.spec)
+		if err != nil {
+			log.Fatalf("Error setting constraints: %v", err)
+		}
+		*c.r = r
+		search = true
+	}
 
 	// Generate the maze paths
-	if err := m.Generate(genSeed, startPoint, endPoint, doorPoint, *doorSide, *bias); err != nil {
+	if search {
+		found, err := m.GenerateMatching(constraints, genSeed, startPoint, endPoint, doorPoint, doorSide, *bias)
+		if err != nil {
+			log.Fatalf("Error generating maze: %v", err)
+		}
+		mt := m.Metrics()
+		fmt.Fprintf(os.Stderr, "Seed: %d (solution %d steps, %d dead ends, %d junctions, %d turns)\n",
+			found, mt.SolutionLength, mt.DeadEnds, mt.Junctions, mt.Turns)
+	} else if err := m.Generate(genSeed, startPoint, endPoint, doorPoint, doorSide, *bias); err != nil {
 		log.Fatalf("Error generating maze: %v", err)
 	}
+	if *braid > 0 {
+		fmt.Fprintf(os.Stderr, "Braid: %d loops added\n", m.BraidLoops())
+	}
+	if *placement != "" {
+		result := m.PlacementResult()
+		fmt.Fprintf(os.Stderr, "Placement: %s, %d steps from start to end\n", result.Strategy, result.Distance)
+	}
 
 	var solutionPath []maze.Point
 	// If the solveRatio flag is set, solve the maze
@@ -80,6 +408,91 @@ func main() {
 	fmt.Println(renderMaze(m, solutionPath, *solveRatio))
 }
 
+// denList collects the dens given with repeated --den flags.
+type denList []maze.Den
+
+// gridMaze is a maze on a grid other than the square one.
+type gridMaze interface {
+	SetGenerator(g maze.Generator) error
+	Generate(seed int64, start, end *maze.Point, bias float64) error
+	Solve() ([]maze.Point, bool)
+	WriteSVG(w io.Writer, cellSize float64, solution []maze.Point) error
+}
+
+// partialPath returns the start of a solution path that shows the given
+// fraction of its steps, like renderMaze does.
+func partialPath[P any](path []P, ratio float64) []P {
+	if len(path) == 0 {
+		return nil
+	}
+	return path[:1+min(int(math.Ceil(float64(len(path)-1)*ratio)), len(path)-1)]
+}
+
+// rejectFlags stops with an error if a flag other than the allowed ones was
+// given on the command line, for a kind of maze that would ignore it.
+func rejectFlags(what string, allowed ...string) {
+	flag.Visit(func(f *flag.Flag) {
+		if !slices.Contains(allowed, f.Name) {
+			log.Fatalf("%s do not support --%s", what, f.Name)
+		}
+	})
+}
+
+// isSet reports whether any of the named flags was given on the command line.
+func isSet(names ...string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if slices.Contains(names, f.Name) {
+			set = true
+		}
+	})
+	return set
+}
+
+// writeSVG creates the named file and draws a maze into it with draw.
+func writeSVG(name string, draw func(f *os.File) error) {
+	f, err := os.Create(name)
+	if err != nil {
+		log.Fatalf("Error writing image: %v", err)
+	}
+	if err := draw(f); err != nil {
+		f.Close()
+		log.Fatalf("Error writing image: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("Error writing image: %v", err)
+	}
+}
+
+// String implements flag.Value.
+func (l *denList) String() string {
+	return ""
+}
+
+// Set implements flag.Value.
+func (l *denList) Set(value string) error {
+	d, err := maze.ParseDen(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, d)
+	return nil
+}
+
+// sideList collects the door sides given with repeated --doorSide flags.
+type sideList []string
+
+// String implements flag.Value.
+func (l *sideList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value.
+func (l *sideList) Set(value string) error {
+	*l = append(*l, strings.ToLower(value))
+	return nil
+}
+
 // renderMaze builds the string representation of the maze.
 // It takes the maze structure and overlays the solution path based on the ratio.
 func renderMaze(m *maze.Maze, path []maze.Point, ratio float64) string {
@@ -92,7 +505,16 @@ func renderMaze(m *maze.Maze, path []maze.Point, ratio float64) string {
 			// math.Ceil ensures that for any ratio > 0, at least one step is shown.
 			pointsToShow := int(math.Ceil(float64(pathLength-1) * ratio))
 			for i := 1; i <= pointsToShow && i < pathLength; i++ {
-				solutionPoints[path[i]] = true
+				p := path[i]
+				solutionPoints[p] = true
+				// A route across a joined border leaves the maze on one side
+				// and comes back on the other, so mark both openings.
+				if m.Wrap()&maze.WrapHorizontal != 0 && (p.X == 0 || p.X == m.Width()-1) {
+					solutionPoints[maze.Point{X: m.Width() - 1 - p.X, Y: p.Y}] = true
+				}
+				if m.Wrap()&maze.WrapVertical != 0 && (p.Y == 0 || p.Y == m.Height()-1) {
+					solutionPoints[maze.Point{X: p.X, Y: m.Height() - 1 - p.Y}] = true
+				}
 			}
 		}
 	}
@@ -105,6 +527,8 @@ func renderMaze(m *maze.Maze, path []maze.Point, ratio float64) string {
 			// If the point is part of the solution path and is a normal path cell, draw it.
 			if solutionPoints[p] && cell == maze.Path {
 				sb.WriteRune(rune(maze.SolutionPath))
+			} else if isOutside(m, p) {
+				sb.WriteRune(' ')
 			} else {
 				sb.WriteRune(rune(cell))
 			}
@@ -113,3 +537,19 @@ func renderMaze(m *maze.Maze, path []maze.Point, ratio float64) string {
 	}
 	return sb.String()
 }
+
+// isOutside reports whether a point is masked and away from every path,
+// so that it can be left blank and the maze shows its shape.
+func isOutside(m *maze.Maze, p maze.Point) bool {
+	if !m.IsMasked(p) {
+		return false
+	}
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if cell, ok := m.Cell(p.X+dx, p.Y+dy); ok && cell != maze.Wall {
+				return false
+			}
+		}
+	}
+	return true
+}
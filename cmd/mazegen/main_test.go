@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the command itself when a test starts this binary again with
+// MAZEGEN_RUN set, so that its output and exit status can be checked.
+func TestMain(m *testing.M) {
+	if os.Getenv("MAZEGEN_RUN") != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// mazegen runs the command with the given arguments and returns what it wrote
+// to standard output and standard error, and whether it succeeded.
+func mazegen(t *testing.T, args ...string) (string, string, bool) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "MAZEGEN_RUN=1")
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if _, failed := err.(*exec.ExitError); err != nil && !failed {
+		t.Fatalf("Failed to run mazegen: %v", err)
+	}
+	return stdout.String(), stderr.String(), err == nil
+}
+
+func TestUnsupportedFlags(t *testing.T) {
+	svg := "--svg=" + filepath.Join(t.TempDir(), "maze.svg")
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--grid=hex", "--braid=0.9"}, "Mazes on a hex grid do not support --braid"},
+		{[]string{"--grid=hexflat", "--solution=10-"}, "Mazes on a hexflat grid do not support --solution"},
+		{[]string{"--grid=hex", "--denHeight=3"}, "Mazes on a hex grid do not support --denHeight"},
+		{[]string{"--grid=hex", "--hbias=0.5"}, "Mazes on a hex grid do not support --hbias"},
+		{[]string{"--grid=hex", "--cellSize=20"}, "Mazes on a hex grid do not support --cellSize"},
+		{[]string{"--grid=polar", svg, "--width=9"}, "Mazes on a polar grid do not support --width"},
+		{[]string{"--grid=polar", svg, "--denHeight=2"}, "Mazes on a polar grid do not support --denHeight"},
+		{[]string{"--grid=polar", svg, "--doors=2"}, "Mazes on a polar grid do not support --doors"},
+		{[]string{"--grid=polar", svg, "--mask=mask.txt"}, "Mazes on a polar grid do not support --mask"},
+		{[]string{"--grid=delta", svg, "--entrance=top"}, "Mazes on a delta grid do not support --entrance"},
+		{[]string{"--grid=delta", svg, "--denShape=circle"}, "Mazes on a delta grid do not support --denShape"},
+		{[]string{"--grid=delta", svg, "--wrap=torus"}, "Mazes on a delta grid do not support --wrap"},
+		{[]string{"--grid=delta", svg, "--depth=2"}, "Mazes on a delta grid do not support --depth"},
+		{[]string{"--depth=2", "--braid=0.5"}, "3D mazes do not support --braid"},
+		{[]string{"--depth=2", "--placement=corners"}, "3D mazes do not support --placement"},
+		{[]string{"--depth=2", "--turns=5-"}, "3D mazes do not support --turns"},
+		{[]string{"--depth=2", svg}, "3D mazes do not support --svg"},
+		{[]string{"--depth=2", "--startZ=1"}, "--startZ needs --startX and --startY"},
+		{[]string{"--endZ=0"}, "--startZ and --endZ need a --depth above 1"},
+		{[]string{svg}, "Square mazes are only drawn as text and do not support --svg or --cellSize"},
+		{[]string{"--cellSize=8"}, "Square mazes are only drawn as text and do not support --svg or --cellSize"},
+		{[]string{"--grid=hex", "--startZ=0"}, "Mazes on a hex grid do not support --startZ"},
+	}
+	for _, tt := range tests {
+		_, stderr, ok := mazegen(t, tt.args...)
+		if ok || !strings.Contains(stderr, tt.want) {
+			t.Errorf("Expected %v to fail with %q, got %q", tt.args, tt.want, stderr)
+		}
+	}
+	for _, args := range [][]string{
+		{"--grid=hex", "--width=7", "--height=5", "--denWidth=2", "--seed=1", "--solveRatio=1"},
+		{"--width=7", "--height=5", "--depth=2", "--seed=1", "--startX=1", "--startY=1", "--algo=kruskal", "--solveRatio=1"},
+		{"--grid=polar", "--height=6", "--denWidth=2", "--seed=1", "--algo=wilson", svg, "--cellSize=10", "--solveRatio=0.5"},
+		{"--grid=delta", "--width=15", "--height=7", "--denWidth=5", "--denHeight=3", "--seed=1", svg, "--solveRatio=1"},
+		{"--grid=hexflat", "--width=7", "--height=5", "--seed=1", "--bias=0.2", "--algo=prim", svg, "--cellSize=8"},
+	} {
+		if _, stderr, ok := mazegen(t, args...); !ok {
+			t.Errorf("Expected %v to succeed, got %q", args, stderr)
+		}
+	}
+}
+
+func TestGridStartAtOrigin(t *testing.T) {
+	for _, args := range [][]string{
+		{"--startX=0", "--startY=0"},
+		{"--startX=0"},
+		{"--startY=0"},
+	} {
+		stdout, stderr, ok := mazegen(t, append([]string{"--grid=hex", "--width=5", "--height=4", "--seed=1"}, args...)...)
+		if !ok {
+			t.Fatalf("Expected %v to succeed, got %q", args, stderr)
+		}
+		// The first row of cells is the second line, and its first cell
+		// is drawn right after the left border.
+		if lines := strings.Split(stdout, "\n"); len(lines) < 2 || !strings.HasPrefix(lines[1], "| S ") {
+			t.Errorf("Expected %v to start in cell (0,0), got\n%s", args, stdout)
+		}
+	}
+}
+
+func TestMaze3DLevels(t *testing.T) {
+	stdout, stderr, ok := mazegen(t, "--width=7", "--height=5", "--depth=3", "--seed=1",
+		"--startX=1", "--startY=1", "--startZ=2", "--endX=5", "--endY=3", "--endZ=0")
+	if !ok {
+		t.Fatalf("Expected a 3D maze, got %q", stderr)
+	}
+	levels := strings.Split(stdout, "Level ")
+	if len(levels) != 4 {
+		t.Fatalf("Expected 3 levels, got\n%s", stdout)
+	}
+	if !strings.Contains(levels[1], "E") || !strings.Contains(levels[3], "S") {
+		t.Errorf("Expected the End on level 0 and the Start on level 2, got\n%s", stdout)
+	}
+}
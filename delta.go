@@ -0,0 +1,159 @@
+package maze
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// DeltaMaze is a maze on a grid of triangles, also known as a delta maze.
+// Each row alternates between triangles that point up and triangles that
+// point down, and every triangle borders up to three others: the ones to its
+// left and right, and the one below its base if it points up or above its
+// base if it points down. Cells are addressed as a Point by column (X) and
+// row (Y); the triangle in the top left corner points up.
+//
+// Like Maze, it can have a den in its center: a block of triangles with a
+// single door.
+type DeltaMaze struct {
+	graphMaze
+	cols int
+	rows int
+}
+
+// NewDelta creates a triangular maze of cols by rows triangles. A den of
+// denCols by denRows triangles is placed in the center if both are above 0.
+func NewDelta(cols, rows, denCols, denRows int) (*DeltaMaze, error) {
+	if cols <= 0 || rows <= 0 {
+		return nil, fmt.Errorf("width and height must be positive")
+	}
+	if denCols < 0 || denRows < 0 {
+		return nil, fmt.Errorf("den dimensions must be non-negative")
+	}
+
+	d := &DeltaMaze{graphMaze: graphMaze{graph: newGraph("triangular", cols*rows)}, cols: cols, rows: rows}
+	d.cellAt = func(p Point) int {
+		if p.X < 0 || p.X >= cols || p.Y < 0 || p.Y >= rows {
+			return -1
+		}
+		return p.Y*cols + p.X
+	}
+	d.pointOf = func(c int) Point {
+		return Point{X: c % cols, Y: c / cols}
+	}
+	// The sides of a triangle are its left and right sides and its base.
+	for c := range d.adj {
+		p := d.pointOf(c)
+		base := Point{X: p.X, Y: p.Y - 1}
+		if d.PointsUp(p) {
+			base.Y = p.Y + 1
+		}
+		d.setNeighbors(c, d.cellAt(Point{X: p.X - 1, Y: p.Y}), d.cellAt(Point{X: p.X + 1, Y: p.Y}), d.cellAt(base))
+	}
+	// Only a passage along a row can go straight on.
+	d.ahead = func(prev, cur int) int {
+		if k := d.side(cur, prev); k < 2 {
+			return d.adj[cur][1-k]
+		}
+		return -1
+	}
+
+	if denCols > 0 && denRows > 0 {
+		// The den must leave a row or column of triangles all around it.
+		x, y := (cols-denCols)/2, (rows-denRows)/2
+		if x < 1 || y < 1 || x+denCols > cols-1 || y+denRows > rows-1 {
+			return nil, fmt.Errorf("den (%dx%d) is too large for a %dx%d grid", denCols, denRows, cols, rows)
+		}
+		for row := y; row < y+denRows; row++ {
+			for col := x; col < x+denCols; col++ {
+				d.den[d.cellAt(Point{X: col, Y: row})] = true
+			}
+		}
+	}
+	d.reset()
+	return d, nil
+}
+
+// Width returns the number of triangles in a row.
+func (d *DeltaMaze) Width() int { return d.cols }
+
+// Height returns the number of rows.
+func (d *DeltaMaze) Height() int { return d.rows }
+
+// PointsUp reports whether the triangle at p points up, with its base at the bottom.
+func (d *DeltaMaze) PointsUp(p Point) bool {
+	return (p.X+p.Y)%2 == 0
+}
+
+// Generate carves a perfect maze with the maze's generator, opens the den's
+// door and places the Start and End. As for Maze, a seed reproduces the maze,
+// and a start or end point that is not given is placed as far as possible
+// from the other. The bias is the chance of carving straight on along a row,
+// for the generators that honour it.
+func (d *DeltaMaze) Generate(seed int64, start, end *Point, bias float64) error {
+	s, err := d.cell(start, "start", false)
+	if err != nil {
+		return err
+	}
+	e, err := d.cell(end, "end", false)
+	if err != nil {
+		return err
+	}
+	return d.generate(seed, d.Generator(), bias, s, e)
+}
+
+// corners returns the corners of the triangle at p, with sides of the given
+// size, such that side k of the triangle runs from corner k to corner k+1
+// and the base from the last corner back to the first.
+func (d *DeltaMaze) corners(p Point, size float64) [3]svgPoint {
+	height := size * math.Sqrt(3) / 2
+	x, y := float64(p.X)*size/2, float64(p.Y)*height
+	if d.PointsUp(p) {
+		// Base left, apex, base right.
+		return [3]svgPoint{{x, y + height}, {x + size/2, y}, {x + size, y + height}}
+	}
+	// Top left, apex, top right.
+	return [3]svgPoint{{x, y}, {x + size/2, y + height}, {x + size, y}}
+}
+
+// WriteSVG draws the maze as an SVG image, with triangles whose sides are
+// cellSize pixels long, and with the cells of solution joined by a line.
+func (d *DeltaMaze) WriteSVG(w io.Writer, cellSize float64, solution []Point) error {
+	if cellSize <= 0 {
+		return fmt.Errorf("cell size must be positive")
+	}
+	margin := cellSize / 2
+	shift := func(p svgPoint) svgPoint { return svgPoint{x: p.x + margin, y: p.y + margin} }
+	middle := func(p Point) svgPoint {
+		cs := d.corners(p, cellSize)
+		return shift(svgPoint{x: (cs[0].x + cs[1].x + cs[2].x) / 3, y: (cs[0].y + cs[1].y + cs[2].y) / 3})
+	}
+	s := &svgWriter{w: w}
+	s.begin(float64(d.cols+1)*cellSize/2+2*margin, float64(d.rows)*cellSize*math.Sqrt(3)/2+2*margin)
+	for c := range d.adj {
+		if d.den[c] {
+			cs := d.corners(d.pointOf(c), cellSize)
+			s.polygon([]svgPoint{shift(cs[0]), shift(cs[1]), shift(cs[2])}, svgDenColor)
+		}
+	}
+	for c := range d.adj {
+		for k, n := range d.adj[c] {
+			// Draw each wall once, from the cell with the lower index.
+			if d.linked[c][k] || (n >= 0 && n < c) {
+				continue
+			}
+			cs := d.corners(d.pointOf(c), cellSize)
+			s.wall(shift(cs[k]), shift(cs[(k+1)%3]))
+		}
+	}
+	path := make([]svgPoint, len(solution))
+	for i, p := range solution {
+		path[i] = middle(p)
+	}
+	s.solution(path)
+	if d.start >= 0 {
+		s.marker(middle(d.Start()), cellSize/6, svgStartColor)
+		s.marker(middle(d.End()), cellSize/6, svgEndColor)
+	}
+	return s.end()
+}
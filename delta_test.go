@@ -0,0 +1,166 @@
+package maze_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// checkDeltaMaze verifies that a triangular maze is a perfect maze outside
+// its den, with every triangle reachable from the Start.
+func checkDeltaMaze(t *testing.T, d *maze.DeltaMaze) {
+	t.Helper()
+	seen := map[maze.Point]bool{d.Start(): true}
+	queue := []maze.Point{d.Start()}
+	passages, cells := 0, 0
+	for head := 0; head < len(queue); head++ {
+		p := queue[head]
+		if !d.IsInsideDen(p) {
+			cells++
+		}
+		for _, n := range d.Neighbors(p) {
+			if !d.Linked(p, n) {
+				continue
+			}
+			if !d.IsInsideDen(p) && !d.IsInsideDen(n) {
+				passages++
+			}
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	if len(seen) != d.Width()*d.Height() {
+		t.Fatalf("Expected all %d triangles to be reachable, got %d", d.Width()*d.Height(), len(seen))
+	}
+	if passages/2 != cells-1 {
+		t.Errorf("Expected %d passages outside the den for a perfect maze, got %d", cells-1, passages/2)
+	}
+}
+
+func TestDeltaMaze(t *testing.T) {
+	for _, algo := range []string{"dfs", "growingtree:random", "huntandkill", "kruskal", "prim", "wilson", "aldousbroder"} {
+		d, err := maze.NewDelta(21, 9, 5, 3)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		g, err := maze.ParseGenerator(algo)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", algo, err)
+		}
+		if err := d.SetGenerator(g); err != nil {
+			t.Fatalf("Expected %s to support triangular grids, got %v", algo, err)
+		}
+		if err := d.Generate(11, nil, nil, 0.5); err != nil {
+			t.Fatalf("Expected no error with %s, but got %v", algo, err)
+		}
+		checkDeltaMaze(t, d)
+		if _, _, ok := d.Door(); !ok {
+			t.Errorf("Expected the den of the %s maze to have a door", algo)
+		}
+		path, found := d.Solve()
+		if !found || path[0] != d.Start() || path[len(path)-1] != d.End() {
+			t.Fatalf("Expected a route from Start to End with %s, got %v", algo, path)
+		}
+		for i := 1; i < len(path); i++ {
+			if !d.Linked(path[i-1], path[i]) {
+				t.Fatalf("Expected the route to follow passages, but %+v and %+v are not linked", path[i-1], path[i])
+			}
+		}
+	}
+}
+
+func TestDeltaMazeNeighbors(t *testing.T) {
+	d, err := maze.NewDelta(6, 4, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	tests := []struct {
+		p         maze.Point
+		up        bool
+		neighbors []maze.Point
+	}{
+		{maze.Point{X: 2, Y: 1}, false, []maze.Point{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 2, Y: 0}}},
+		{maze.Point{X: 3, Y: 1}, true, []maze.Point{{X: 2, Y: 1}, {X: 4, Y: 1}, {X: 3, Y: 2}}},
+		{maze.Point{X: 0, Y: 0}, true, []maze.Point{{X: 1, Y: 0}, {X: 0, Y: 1}}},
+	}
+	for _, tt := range tests {
+		if d.PointsUp(tt.p) != tt.up {
+			t.Errorf("Expected PointsUp(%+v) to be %v", tt.p, tt.up)
+		}
+		got := d.Neighbors(tt.p)
+		if len(got) != len(tt.neighbors) {
+			t.Errorf("Expected %+v to have neighbors %v, got %v", tt.p, tt.neighbors, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.neighbors[i] {
+				t.Errorf("Expected %+v to have neighbors %v, got %v", tt.p, tt.neighbors, got)
+				break
+			}
+		}
+	}
+}
+
+func TestDeltaMazePoints(t *testing.T) {
+	d, err := maze.NewDelta(15, 7, 3, 1)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	start := maze.Point{X: 0, Y: 0}
+	if err := d.Generate(4, &start, nil, 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if d.Start() != start {
+		t.Errorf("Expected Start %+v, got %+v", start, d.Start())
+	}
+	checkDeltaMaze(t, d)
+
+	inside := maze.Point{X: 7, Y: 3}
+	if err := d.Generate(4, nil, &inside, 0.5); err == nil {
+		t.Errorf("Expected an error for an end point inside the den")
+	}
+	if _, err := maze.NewDelta(7, 3, 6, 1); err == nil {
+		t.Errorf("Expected an error for a den that touches the border")
+	}
+	if err := d.SetGenerator(maze.BinaryTree{}); err == nil || !strings.Contains(err.Error(), "triangular") {
+		t.Errorf("Expected the binary tree to be rejected for a triangular grid, got %v", err)
+	}
+}
+
+func TestDeltaMazeSVG(t *testing.T) {
+	d, err := maze.NewDelta(8, 4, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := d.Generate(6, nil, nil, 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	path, _ := d.Solve()
+	var buf bytes.Buffer
+	if err := d.WriteSVG(&buf, 20, path); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	svg := buf.String()
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Errorf("Expected an SVG document, got:\n%s", svg)
+	}
+	// Every side of every triangle is a wall, except the 31 passages of a
+	// perfect maze of 32 triangles.
+	border, inner := 0, 0
+	for y := 0; y < d.Height(); y++ {
+		for x := 0; x < d.Width(); x++ {
+			n := len(d.Neighbors(maze.Point{X: x, Y: y}))
+			border, inner = border+3-n, inner+n
+		}
+	}
+	if walls, want := strings.Count(svg, "<line"), border+inner/2-31; walls != want {
+		t.Errorf("Expected %d walls, got %d", want, walls)
+	}
+	if !strings.Contains(svg, "<polyline") || strings.Count(svg, "<circle") != 2 {
+		t.Errorf("Expected a solution line and two markers")
+	}
+}
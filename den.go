@@ -0,0 +1,234 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Den is a room inside the maze. It is left open, surrounded by a wall, and
+// connected to the maze through one or more doors. A den fills its rectangle
+// unless it is given another shape.
+type Den struct {
+	// X and Y are the top-left corner of the den. They are aligned to odd
+	// coordinates like the maze paths, and ignored for automatic dens.
+	X, Y int
+	// Width and Height are the size of the den's bounding rectangle, adjusted to be odd.
+	Width, Height int
+	// Shape selects the cells of the rectangle that belong to the den.
+	// Nil fills the whole rectangle.
+	Shape DenShape
+	// Auto places the den at a random free position each time the maze is generated.
+	Auto bool
+	// Role makes the den hold the maze's Start or End.
+	Role DenRole
+	// RoleCell is the column and row, as in NewCellSet, of the cell that gets
+	// the Start or End marker. Nil uses the cell closest to the den's center.
+	RoleCell *Point
+	// DoorPlan decides how many doors the den has and where they go.
+	// The zero plan opens a single door at random.
+	DoorPlan
+
+	// placed is set once an automatic den has got its position.
+	placed bool
+}
+
+// located reports whether the den has a position: automatic dens only get
+// one when the maze is generated.
+func (d Den) located() bool {
+	return !d.Auto || d.placed
+}
+
+// contains reports whether a point lies inside the den.
+func (d Den) contains(p Point) bool {
+	return d.located() && d.covers(p)
+}
+
+// overlaps reports whether two dens are too close, that is whether their
+// rectangles overlap or do not leave at least one maze cell between their walls.
+func (d Den) overlaps(o Den) bool {
+	return d.X < o.X+o.Width+3 && o.X < d.X+d.Width+3 &&
+		d.Y < o.Y+o.Height+3 && o.Y < d.Y+d.Height+3
+}
+
+// AddDen adds another den to the maze. Its size is adjusted to be odd and an
+// explicit position is moved up and left, if needed, to odd coordinates.
+// The den must fit inside the maze walls and keep clear of the other dens.
+func (m *Maze) AddDen(d Den) error {
+	if d.Width <= 0 || d.Height <= 0 {
+		return fmt.Errorf("den dimensions must be positive")
+	}
+	d.Width, d.Height = adjustToOdd(d.Width), adjustToOdd(d.Height)
+	if d.Width >= m.width-2 {
+		return fmt.Errorf("den width (%d) is too large for the maze width (%d)", d.Width, m.width)
+	}
+	if d.Height >= m.height-2 {
+		return fmt.Errorf("den height (%d) is too large for the maze height (%d)", d.Height, m.height)
+	}
+	if err := d.DoorPlan.check(); err != nil {
+		return err
+	}
+	if err := d.checkShape(); err != nil {
+		return err
+	}
+	if err := d.checkRole(m.dens); err != nil {
+		return err
+	}
+
+	if d.Auto {
+		d.X, d.Y, d.placed = 0, 0, false
+	} else {
+		if d.X%2 == 0 {
+			d.X--
+		}
+		if d.Y%2 == 0 {
+			d.Y--
+		}
+		if err := m.checkDenPosition(d, m.dens); err != nil {
+			return err
+		}
+	}
+
+	m.dens = append(m.dens, d)
+	m.initializeGrid()
+	return nil
+}
+
+// checkDenPosition verifies that a den lies within the maze walls, clear of
+// the given other dens and of the mask.
+func (m *Maze) checkDenPosition(d Den, others []Den) error {
+	if d.X < 1 || d.Y < 1 || d.X+d.Width > m.width-1 || d.Y+d.Height > m.height-1 {
+		return fmt.Errorf("den at %d,%d of size %dx%d does not fit inside the maze", d.X, d.Y, d.Width, d.Height)
+	}
+	for _, o := range others {
+		if o.located() && d.overlaps(o) {
+			return fmt.Errorf("den at %d,%d overlaps or touches the den at %d,%d", d.X, d.Y, o.X, o.Y)
+		}
+	}
+	for y := d.Y; y < d.Y+d.Height; y++ {
+		for x := d.X; x < d.X+d.Width; x++ {
+			if p := (Point{X: x, Y: y}); d.covers(p) && m.IsMasked(p) {
+				return fmt.Errorf("den at %d,%d is covered by the mask", d.X, d.Y)
+			}
+		}
+	}
+	return nil
+}
+
+// Dens returns all dens of the maze, starting with the central den created by New.
+// Automatic dens report the position they got in the last generation.
+func (m *Maze) Dens() []Den {
+	return append([]Den(nil), m.dens...)
+}
+
+// Doors returns the door points of all dens, in the order of Dens.
+func (m *Maze) Doors() []Point {
+	var doors []Point
+	for _, d := range m.doors {
+		doors = append(doors, d...)
+	}
+	return doors
+}
+
+// DenDoors returns the door points of the den with the given index in Dens.
+func (m *Maze) DenDoors(i int) []Point {
+	if i < 0 || i >= len(m.doors) {
+		return nil
+	}
+	return append([]Point(nil), m.doors[i]...)
+}
+
+// placeAutoDens picks a random free position for every automatic den,
+// one after the other, so that each keeps clear of those placed before it.
+func (m *Maze) placeAutoDens(r *rand.Rand) error {
+	for i := range m.dens {
+		m.dens[i].placed = false
+	}
+	for i, d := range m.dens {
+		if !d.Auto {
+			continue
+		}
+
+		var positions []Point
+		for y := 1; y+d.Height <= m.height-1; y += 2 {
+			for x := 1; x+d.Width <= m.width-1; x += 2 {
+				d.X, d.Y = x, y
+				if m.checkDenPosition(d, m.dens) == nil {
+					positions = append(positions, Point{X: x, Y: y})
+				}
+			}
+		}
+		if len(positions) == 0 {
+			return fmt.Errorf("no room left for a den of size %dx%d", d.Width, d.Height)
+		}
+		p := positions[r.Intn(len(positions))]
+		m.dens[i].X, m.dens[i].Y, m.dens[i].placed = p.X, p.Y, true
+	}
+	return nil
+}
+
+// ParseDen parses a den description of the form "WxH" for an automatically
+// placed den or "WxH@X,Y" for a den at a given position. The size may be
+// followed by "/shape" for a den shape (rect, circle, cross or l, where a
+// circle can have a radius in cells as "circle:r=N"), and the description by
+// ":side" for the door side, or several comma-separated sides for several
+// doors, for example "9x9/circle@3,3:top", "9x9/circle:r=1" or "7x5:left,right".
+func ParseDen(spec string) (Den, error) {
+	var d Den
+	spec = strings.TrimSpace(spec)
+	var sides string
+	// The door sides follow the last colon, unless it starts a shape parameter.
+	if i := strings.LastIndex(spec, ":"); i >= 0 && !strings.Contains(spec[i+1:], "=") {
+		spec, sides = spec[:i], spec[i+1:]
+	}
+	if strings.TrimSpace(sides) != "" {
+		for _, side := range strings.Split(sides, ",") {
+			d.DoorSides = append(d.DoorSides, strings.ToLower(strings.TrimSpace(side)))
+		}
+	}
+	size, pos, hasPos := strings.Cut(spec, "@")
+	size, shape, hasShape := strings.Cut(size, "/")
+	if hasShape {
+		s, err := parseDenShape(shape)
+		if err != nil {
+			return Den{}, err
+		}
+		d.Shape = s
+	}
+
+	w, h, ok := strings.Cut(size, "x")
+	var err error
+	if ok {
+		if d.Width, err = strconv.Atoi(strings.TrimSpace(w)); err == nil {
+			d.Height, err = strconv.Atoi(strings.TrimSpace(h))
+		}
+	}
+	if !ok || err != nil {
+		return Den{}, fmt.Errorf("invalid den size %q: use WxH", size)
+	}
+
+	if !hasPos {
+		d.Auto = true
+		return d, nil
+	}
+	x, y, ok := strings.Cut(pos, ",")
+	if ok {
+		if d.X, err = strconv.Atoi(strings.TrimSpace(x)); err == nil {
+			d.Y, err = strconv.Atoi(strings.TrimSpace(y))
+		}
+	}
+	if !ok || err != nil {
+		return Den{}, fmt.Errorf("invalid den position %q: use X,Y", pos)
+	}
+	return d, nil
+}
+
+// validDoorSide reports whether a door side name is known.
+func validDoorSide(side string) bool {
+	switch side {
+	case "top", "bottom", "left", "right":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package maze_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestAddDen(t *testing.T) {
+	m, err := maze.New(41, 31, 7, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+
+	if err := m.AddDen(maze.Den{X: 3, Y: 3, Width: 5, Height: 3, DoorPlan: maze.DoorPlan{DoorSides: []string{"right"}}}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	// An even position is moved to odd coordinates and an even size is made odd.
+	if err := m.AddDen(maze.Den{X: 32, Y: 22, Width: 4, Height: 4}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	dens := m.Dens()
+	if len(dens) != 3 {
+		t.Fatalf("Expected 3 dens, got %d", len(dens))
+	}
+	if dens[2].X != 31 || dens[2].Y != 21 || dens[2].Width != 5 || dens[2].Height != 5 {
+		t.Errorf("Expected the third den at 31,21 with size 5x5, got %+v", dens[2])
+	}
+	if !m.IsInsideDen(maze.Point{X: 4, Y: 4}) || !m.IsAdjacentToDen(maze.Point{X: 8, Y: 4}) {
+		t.Error("Expected IsInsideDen and IsAdjacentToDen to cover every den")
+	}
+
+	testCases := []struct {
+		name string
+		den  maze.Den
+	}{
+		{"Overlapping", maze.Den{X: 5, Y: 5, Width: 3, Height: 3}},
+		{"Walls touching", maze.Den{X: 9, Y: 3, Width: 3, Height: 3}},
+		{"Outside the maze", maze.Den{X: 39, Y: 3, Width: 3, Height: 3}},
+		{"Too large", maze.Den{X: 1, Y: 1, Width: 41, Height: 3}},
+		{"Empty", maze.Den{X: 1, Y: 1}},
+		{"Invalid door side", maze.Den{X: 3, Y: 25, Width: 3, Height: 3, DoorPlan: maze.DoorPlan{DoorSides: []string{"up"}}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := m.AddDen(tc.den); err == nil {
+				t.Errorf("Expected error for den %+v, but got nil", tc.den)
+			}
+		})
+	}
+	if len(m.Dens()) != 3 {
+		t.Errorf("Expected rejected dens not to be added, got %d dens", len(m.Dens()))
+	}
+
+	if err := m.Generate(3, nil, nil, nil, "top", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	checkPerfectMaze(t, m)
+
+	doors := m.Doors()
+	if len(doors) != 3 {
+		t.Fatalf("Expected a door for each den, got %v", doors)
+	}
+	if doors[0] != (maze.Point{X: 20, Y: 12}) {
+		t.Errorf("Expected the central den's door on top at {20, 12}, got %+v", doors[0])
+	}
+	if doors[1] != (maze.Point{X: 8, Y: 4}) {
+		t.Errorf("Expected the second den's door on the right at {8, 4}, got %+v", doors[1])
+	}
+	if m.Door() != doors[0] {
+		t.Errorf("Expected Door to return the first den's door, got %+v", m.Door())
+	}
+}
+
+func TestAutoDens(t *testing.T) {
+	generate := func(seed int64) *maze.Maze {
+		m, err := maze.New(41, 31, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		for i := 0; i < 4; i++ {
+			if err := m.AddDen(maze.Den{Width: 5, Height: 5, Auto: true}); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+		}
+		if m.IsInsideDen(maze.Point{X: 1, Y: 1}) {
+			t.Error("Expected automatic dens to take no space before generation")
+		}
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		return m
+	}
+
+	m := generate(5)
+	checkPerfectMaze(t, m)
+	dens := m.Dens()
+	for i, d := range dens {
+		if !m.IsInsideDen(maze.Point{X: d.X, Y: d.Y}) {
+			t.Errorf("Expected den %d to be placed, got %+v", i, d)
+		}
+		for _, o := range dens[i+1:] {
+			if d.X < o.X+o.Width+3 && o.X < d.X+d.Width+3 && d.Y < o.Y+o.Height+3 && o.Y < d.Y+d.Height+3 {
+				t.Errorf("Expected dens %+v and %+v to keep apart", d, o)
+			}
+		}
+	}
+
+	if gridString(generate(5)) != gridString(m) {
+		t.Error("Expected automatic dens to be placed reproducibly")
+	}
+
+	crowded, err := maze.New(11, 11, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := crowded.AddDen(maze.Den{Width: 5, Height: 5, Auto: true}); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+	}
+	if err := crowded.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error when the dens do not fit, but got nil")
+	}
+}
+
+func TestParseDen(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected maze.Den
+	}{
+		{"7x5", maze.Den{Width: 7, Height: 5, Auto: true}},
+		{"7x5@3,9", maze.Den{X: 3, Y: 9, Width: 7, Height: 5}},
+		{"3x3@5,5:Left", maze.Den{X: 5, Y: 5, Width: 3, Height: 3, DoorPlan: maze.DoorPlan{DoorSides: []string{"left"}}}},
+		{"3x3:top", maze.Den{Width: 3, Height: 3, Auto: true, DoorPlan: maze.DoorPlan{DoorSides: []string{"top"}}}},
+	}
+	for _, tc := range testCases {
+		d, err := maze.ParseDen(tc.spec)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, but got %v", tc.spec, err)
+		}
+		if !reflect.DeepEqual(d, tc.expected) {
+			t.Errorf("ParseDen(%q) = %+v; want %+v", tc.spec, d, tc.expected)
+		}
+	}
+
+	for _, spec := range []string{"7", "7xq", "7x5@3", "7x5@a,b"} {
+		if _, err := maze.ParseDen(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package maze
+
+import (
+	"fmt"
+	"math"
+)
+
+// DenRole makes a den the room where the maze starts or ends, such as a
+// treasure room to reach. Every route to or from the room passes through one
+// of its doors, since the den's wall is closed everywhere else.
+type DenRole int
+
+const (
+	// PlainDen is a den without Start or End.
+	PlainDen DenRole = iota
+	// StartDen holds the maze's Start.
+	StartDen
+	// EndDen holds the maze's End.
+	EndDen
+)
+
+// SetDenRole makes the den with the given index in Dens hold the maze's Start
+// or End, at the given cell or, if cell is nil, at the cell closest to the
+// den's center. Cells are given by column and row as in NewCellSet.
+// The automatic counterpart is placed as far as possible from the den's doors.
+func (m *Maze) SetDenRole(i int, role DenRole, cell *Point) error {
+	if i < 0 || i >= len(m.dens) {
+		return fmt.Errorf("den %d does not exist", i)
+	}
+	d := m.dens[i]
+	d.Role, d.RoleCell = role, cell
+	others := append(append([]Den(nil), m.dens[:i]...), m.dens[i+1:]...)
+	if err := d.checkRole(others); err != nil {
+		return err
+	}
+	m.dens[i] = d
+	return nil
+}
+
+// checkRole verifies that a den's role cell belongs to it and that no other
+// den has the same role.
+func (d Den) checkRole(others []Den) error {
+	if d.Role < PlainDen || d.Role > EndDen {
+		return fmt.Errorf("invalid den role: %d", d.Role)
+	}
+	if d.Role == PlainDen {
+		return nil
+	}
+	if d.RoleCell != nil && !d.hasCell(d.RoleCell.X, d.RoleCell.Y) {
+		return fmt.Errorf("cell %+v is not part of the den", *d.RoleCell)
+	}
+	for _, o := range others {
+		if o.Role == d.Role {
+			return fmt.Errorf("only one den can hold the maze's %s", d.Role.marker())
+		}
+	}
+	return nil
+}
+
+// marker names the maze marker a den role places.
+func (r DenRole) marker() string {
+	if r == StartDen {
+		return "start"
+	}
+	return "end"
+}
+
+// roleCell returns the grid point of the den's role cell.
+func (d Den) roleCell() Point {
+	if d.RoleCell != nil {
+		return Point{X: d.X + 2*d.RoleCell.X, Y: d.Y + 2*d.RoleCell.Y}
+	}
+
+	// Pick the cell closest to the center, the first one in row-major order on a tie.
+	cols, rows := (d.Width+1)/2, (d.Height+1)/2
+	cx, cy := float64(cols-1)/2, float64(rows-1)/2
+	best, bestDist := Point{}, math.Inf(1)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			dist := math.Hypot(float64(col)-cx, float64(row)-cy)
+			if d.hasCell(col, row) && dist < bestDist {
+				best, bestDist = Point{X: col, Y: row}, dist
+			}
+		}
+	}
+	return Point{X: d.X + 2*best.X, Y: d.Y + 2*best.Y}
+}
+
+// denWithRole returns the index of the den with the given role, or -1 if there is none.
+func (m *Maze) denWithRole(role DenRole) int {
+	for i, d := range m.dens {
+		if d.Role == role {
+			return i
+		}
+	}
+	return -1
+}
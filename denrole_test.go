@@ -0,0 +1,153 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// pathDistances returns the distance along the maze paths from a point to every reachable point.
+func pathDistances(m *maze.Maze, from maze.Point) map[maze.Point]int {
+	distances := map[maze.Point]int{from: 0}
+	queue := []maze.Point{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dir := range []maze.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}} {
+			next := maze.Point{X: current.X + dir.X, Y: current.Y + dir.Y}
+			if cell, ok := m.Cell(next.X, next.Y); !ok || cell == maze.Wall {
+				continue
+			}
+			if _, seen := distances[next]; !seen {
+				distances[next] = distances[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distances
+}
+
+// checkDenRoute verifies that the solution leaves or enters the den through one of its doors.
+func checkDenRoute(t *testing.T, m *maze.Maze, den int) {
+	t.Helper()
+	path, found := m.Solve()
+	if !found {
+		t.Fatal("Expected the maze to be solvable")
+	}
+	doors := make(map[maze.Point]bool)
+	for _, door := range m.DenDoors(den) {
+		doors[door] = true
+	}
+	for _, p := range path {
+		if doors[p] {
+			return
+		}
+	}
+	t.Errorf("Expected the solution to pass through a door of den %d", den)
+}
+
+func TestEndDen(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		m, err := maze.New(41, 31, 9, 5)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetDenRole(0, maze.EndDen, nil); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+
+		if m.End() != (maze.Point{X: 19, Y: 15}) {
+			t.Errorf("Expected the End at the den's center {19, 15}, got %+v", m.End())
+		}
+		if cell, _ := m.Cell(19, 15); cell != maze.End {
+			t.Errorf("Expected the End marker in the den, got %q", cell)
+		}
+		if m.IsInsideDen(m.Start()) {
+			t.Errorf("Expected the Start outside the den, got %+v", m.Start())
+		}
+		checkDenRoute(t, m, 0)
+
+		// The Start is the maze cell farthest from the door.
+		distances := pathDistances(m, m.Door())
+		for p, dist := range distances {
+			if dist > distances[m.Start()] && p.X%2 == 1 && p.Y%2 == 1 && !m.IsInsideDen(p) {
+				t.Errorf("Seed %d: %+v is farther from the door than the Start %+v", seed, p, m.Start())
+				break
+			}
+		}
+	}
+}
+
+func TestStartDen(t *testing.T) {
+	m, err := maze.New(41, 31, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	start := maze.Den{X: 3, Y: 3, Width: 5, Height: 5, Role: maze.StartDen, RoleCell: &maze.Point{X: 0, Y: 2}}
+	end := maze.Den{X: 29, Y: 21, Width: 7, Height: 5, Role: maze.EndDen, DoorPlan: maze.DoorPlan{DoorCount: 2}}
+	for _, d := range []maze.Den{start, end} {
+		if err := m.AddDen(d); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+	}
+	if err := m.Generate(7, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.Start() != (maze.Point{X: 3, Y: 7}) {
+		t.Errorf("Expected the Start at {3, 7}, got %+v", m.Start())
+	}
+	if m.End() != (maze.Point{X: 31, Y: 23}) {
+		t.Errorf("Expected the End at the second den's center {31, 23}, got %+v", m.End())
+	}
+	checkPerfectMaze(t, m)
+	checkDenRoute(t, m, 0)
+	checkDenRoute(t, m, 1)
+
+	// An automatic counterpart is placed away from the den's door.
+	m, err = maze.New(41, 31, 9, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetDenRole(0, maze.StartDen, nil); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(3, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.IsInsideDen(m.End()) || m.End() == m.Start() {
+		t.Errorf("Expected the End outside the den, got %+v", m.End())
+	}
+}
+
+func TestDenRoleErrors(t *testing.T) {
+	m, err := maze.New(41, 31, 9, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetDenRole(0, maze.EndDen, &maze.Point{X: 5, Y: 0}); err == nil {
+		t.Error("Expected error for a cell outside the den, but got nil")
+	}
+	if err := m.SetDenRole(0, maze.DenRole(9), nil); err == nil {
+		t.Error("Expected error for an unknown role, but got nil")
+	}
+	if err := m.SetDenRole(1, maze.EndDen, nil); err == nil {
+		t.Error("Expected error for a den that does not exist, but got nil")
+	}
+
+	if err := m.SetDenRole(0, maze.EndDen, &maze.Point{X: 0, Y: 0}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.AddDen(maze.Den{X: 3, Y: 3, Width: 3, Height: 3, Role: maze.EndDen}); err == nil {
+		t.Error("Expected error for a second den holding the End, but got nil")
+	}
+	if err := m.SetDenShape(0, maze.Circle{}); err == nil {
+		t.Error("Expected error when the new shape drops the End cell, but got nil")
+	}
+	end := maze.Point{X: 1, Y: 1}
+	if err := m.Generate(1, nil, &end, nil, "", 0.5); err == nil {
+		t.Error("Expected error for an End point while the den holds the End, but got nil")
+	}
+}
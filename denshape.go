@@ -0,0 +1,222 @@
+package maze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DenShape decides which cells of a den's bounding rectangle belong to the den.
+// Shapes are evaluated on maze cells rather than on grid points, so that the
+// den always lines up with the paths and keeps a wall ring around it: the
+// walls between two den cells, and the pillars between four, are part of the
+// den too. A nil shape fills the whole rectangle.
+type DenShape interface {
+	// Contains reports whether the cell in column col and row row belongs to
+	// a den that is cols cells wide and rows cells high.
+	Contains(col, row, cols, rows int) bool
+}
+
+// Rectangle fills the whole den. It is the shape of dens without one.
+type Rectangle struct{}
+
+// Contains implements DenShape.
+func (Rectangle) Contains(col, row, cols, rows int) bool {
+	return true
+}
+
+// Circle is a disc inscribed in the den, or an ellipse if the den is not square.
+// A circle of radius r fits a den of size 2r+1.
+type Circle struct {
+	// Radius is the radius of a disc centered in the den, in cells, which
+	// must fit the den: a radius of r needs at least 2r+1 cells each way.
+	// Zero inscribes the circle in the den.
+	Radius int
+}
+
+// Contains implements DenShape.
+func (c Circle) Contains(col, row, cols, rows int) bool {
+	rx, ry := float64(cols)/2, float64(rows)/2
+	if c.Radius > 0 {
+		rx, ry = float64(c.Radius)+0.5, float64(c.Radius)+0.5
+	}
+	dx := (float64(col) - float64(cols-1)/2) / rx
+	dy := (float64(row) - float64(rows-1)/2) / ry
+	return dx*dx+dy*dy <= 1
+}
+
+// Cross is a plus sign: a vertical and a horizontal bar through the middle of the den.
+type Cross struct {
+	// Arm is the thickness of the bars in cells. Zero uses a third of the den.
+	Arm int
+}
+
+// Contains implements DenShape.
+func (c Cross) Contains(col, row, cols, rows int) bool {
+	inBar := func(i, n int) bool {
+		arm := c.Arm
+		if arm <= 0 {
+			arm = max(n/3, 1)
+		}
+		first := (n - min(arm, n)) / 2
+		return i >= first && i < first+arm
+	}
+	return inBar(col, cols) || inBar(row, rows)
+}
+
+// LShape is the letter L: a bar down the left side of the den and a bar along its bottom.
+type LShape struct {
+	// Arm is the thickness of the bars in cells. Zero uses half of the den.
+	Arm int
+}
+
+// Contains implements DenShape.
+func (l LShape) Contains(col, row, cols, rows int) bool {
+	arm := l.Arm
+	if arm <= 0 {
+		arm = max(min(cols, rows)/2, 1)
+	}
+	return col < arm || row >= rows-arm
+}
+
+// CellSet is a den of arbitrary shape given cell by cell.
+type CellSet struct {
+	cells map[Point]bool
+}
+
+// NewCellSet creates a den shape from a list of cells. Each cell is given by
+// its column and row in the den, counted in maze cells from the top-left cell,
+// so that a den of size WxH has (W+1)/2 columns and (H+1)/2 rows.
+func NewCellSet(cells ...Point) *CellSet {
+	s := &CellSet{cells: make(map[Point]bool, len(cells))}
+	for _, c := range cells {
+		s.cells[c] = true
+	}
+	return s
+}
+
+// Contains implements DenShape.
+func (s *CellSet) Contains(col, row, cols, rows int) bool {
+	return s.cells[Point{X: col, Y: row}]
+}
+
+// denShapeNames maps the shape names accepted by ParseDen to shapes.
+var denShapeNames = map[string]DenShape{"rect": Rectangle{}, "circle": Circle{}, "cross": Cross{}, "l": LShape{}}
+
+// parseDenShape returns the shape with the given name. A circle can be
+// followed by ":r=N" for its radius.
+func parseDenShape(spec string) (DenShape, error) {
+	name, param, hasParam := strings.Cut(spec, ":")
+	s, ok := denShapeNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown den shape: %q. use 'rect', 'circle', 'cross', or 'l'", name)
+	}
+	if !hasParam {
+		return s, nil
+	}
+	if _, isCircle := s.(Circle); !isCircle {
+		return nil, fmt.Errorf("den shape %q takes no parameters", strings.TrimSpace(name))
+	}
+	key, value, _ := strings.Cut(param, "=")
+	radius, err := strconv.Atoi(strings.TrimSpace(value))
+	if strings.TrimSpace(key) != "r" || err != nil || radius <= 0 {
+		return nil, fmt.Errorf("invalid circle parameter %q: use r=N with a positive N", param)
+	}
+	return Circle{Radius: radius}, nil
+}
+
+// hasCell reports whether the cell in column col and row row of the den
+// belongs to it.
+func (d Den) hasCell(col, row int) bool {
+	cols, rows := (d.Width+1)/2, (d.Height+1)/2
+	if col < 0 || col >= cols || row < 0 || row >= rows {
+		return false
+	}
+	return d.Shape == nil || d.Shape.Contains(col, row, cols, rows)
+}
+
+// covers reports whether a point belongs to the den's shape at its current
+// position. A point between cells belongs to it if all cells around it do.
+func (d Den) covers(p Point) bool {
+	x, y := p.X-d.X, p.Y-d.Y
+	if d.Width <= 0 || d.Height <= 0 || x < 0 || x >= d.Width || y < 0 || y >= d.Height {
+		return false
+	}
+	if d.Shape == nil {
+		return true
+	}
+	for _, col := range []int{x / 2, (x + 1) / 2} {
+		for _, row := range []int{y / 2, (y + 1) / 2} {
+			if !d.hasCell(col, row) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkShape verifies that the den's shape has at least one cell and that its
+// cells are connected, so that a single door reaches all of them.
+func (d Den) checkShape() error {
+	cols, rows := (d.Width+1)/2, (d.Height+1)/2
+	if c, ok := d.Shape.(Circle); ok && (c.Radius < 0 || 2*c.Radius+1 > min(cols, rows)) {
+		return fmt.Errorf("circle radius (%d) does not fit a den of %dx%d cells", c.Radius, cols, rows)
+	}
+	total := 0
+	var first *Point
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if d.hasCell(col, row) {
+				total++
+				if first == nil {
+					first = &Point{X: col, Y: row}
+				}
+			}
+		}
+	}
+	if first == nil {
+		return fmt.Errorf("den shape has no cells")
+	}
+
+	seen := map[Point]bool{*first: true}
+	queue := []Point{*first}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			next := Point{X: c.X + dir.X, Y: c.Y + dir.Y}
+			if !seen[next] && d.hasCell(next.X, next.Y) {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	if len(seen) != total {
+		return fmt.Errorf("den shape is not connected")
+	}
+	return nil
+}
+
+// SetDenShape changes the shape of the den with the given index in Dens,
+// for example to give the central den created by New another shape.
+func (m *Maze) SetDenShape(i int, s DenShape) error {
+	if i < 0 || i >= len(m.dens) {
+		return fmt.Errorf("den %d does not exist", i)
+	}
+	d := m.dens[i]
+	d.Shape = s
+	if err := d.checkShape(); err != nil {
+		return err
+	}
+	if d.RoleCell != nil && !d.hasCell(d.RoleCell.X, d.RoleCell.Y) {
+		return fmt.Errorf("the den's %s cell %+v is not part of the new shape", d.Role.marker(), *d.RoleCell)
+	}
+	if d.located() {
+		if err := m.checkDenPosition(d, nil); err != nil {
+			return err
+		}
+	}
+	m.dens[i] = d
+	m.initializeGrid()
+	return nil
+}
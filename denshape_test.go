@@ -0,0 +1,220 @@
+package maze_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestDenShapeContains(t *testing.T) {
+	testCases := []struct {
+		name     string
+		shape    maze.DenShape
+		col, row int
+		expected bool
+	}{
+		{"Rectangle corner", maze.Rectangle{}, 0, 0, true},
+		{"Circle center", maze.Circle{}, 2, 2, true},
+		{"Circle edge", maze.Circle{}, 0, 2, true},
+		{"Circle corner", maze.Circle{}, 0, 0, false},
+		{"Small circle center", maze.Circle{Radius: 1}, 2, 2, true},
+		{"Small circle diagonal", maze.Circle{Radius: 1}, 1, 1, true},
+		{"Small circle edge", maze.Circle{Radius: 1}, 0, 2, false},
+		{"Cross center", maze.Cross{}, 2, 2, true},
+		{"Cross arm", maze.Cross{}, 2, 0, true},
+		{"Cross corner", maze.Cross{}, 0, 0, false},
+		{"Wide cross", maze.Cross{Arm: 3}, 1, 0, true},
+		{"L bottom left", maze.LShape{}, 0, 4, true},
+		{"L bottom right", maze.LShape{}, 4, 4, true},
+		{"L top right", maze.LShape{}, 4, 0, false},
+		{"Cell set member", maze.NewCellSet(maze.Point{X: 1, Y: 3}), 1, 3, true},
+		{"Cell set non-member", maze.NewCellSet(maze.Point{X: 1, Y: 3}), 3, 1, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.shape.Contains(tc.col, tc.row, 5, 5); got != tc.expected {
+				t.Errorf("Contains(%d, %d, 5, 5) = %v; want %v", tc.col, tc.row, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestShapedDens(t *testing.T) {
+	// A U-shaped den: two columns joined along the bottom.
+	u := maze.NewCellSet(
+		maze.Point{X: 0, Y: 0}, maze.Point{X: 0, Y: 1}, maze.Point{X: 0, Y: 2},
+		maze.Point{X: 1, Y: 2},
+		maze.Point{X: 2, Y: 0}, maze.Point{X: 2, Y: 1}, maze.Point{X: 2, Y: 2},
+	)
+	shapes := map[string]maze.DenShape{
+		"Circle": maze.Circle{},
+		"Cross":  maze.Cross{},
+		"L":      maze.LShape{},
+		"U":      u,
+	}
+	for name, shape := range shapes {
+		t.Run(name, func(t *testing.T) {
+			for _, side := range []string{"", "top", "bottom", "left", "right"} {
+				for seed := int64(1); seed <= 3; seed++ {
+					m, err := maze.New(41, 31, 0, 0)
+					if err != nil {
+						t.Fatalf("Failed to create maze: %v", err)
+					}
+					den := maze.Den{X: 15, Y: 11, Width: 9, Height: 9, Shape: shape}
+					if name == "U" {
+						den.Width, den.Height = 5, 5
+					}
+					if err := m.AddDen(den); err != nil {
+						t.Fatalf("Expected no error, but got %v", err)
+					}
+					if err := m.Generate(seed, nil, nil, nil, side, 0.5); err != nil {
+						t.Fatalf("Expected no error for door side %q, but got %v", side, err)
+					}
+					checkPerfectMaze(t, m)
+				}
+			}
+		})
+	}
+
+	m, err := maze.New(41, 31, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.AddDen(maze.Den{X: 15, Y: 11, Width: 9, Height: 9, Shape: maze.Circle{}}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.IsInsideDen(maze.Point{X: 15, Y: 11}) || !m.IsInsideDen(maze.Point{X: 19, Y: 15}) {
+		t.Error("Expected a circular den to leave out its corners and include its center")
+	}
+	if cell, _ := m.Cell(15, 11); cell != maze.Wall {
+		t.Errorf("Expected the corner of a circular den to start as a wall, got %q", cell)
+	}
+}
+
+func TestShapedDenDoorFallback(t *testing.T) {
+	m, err := maze.New(41, 31, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	// In the middle of its right side, an L only has its left bar, so the door
+	// opens from the bar into the space the L wraps around.
+	if err := m.AddDen(maze.Den{X: 15, Y: 11, Width: 9, Height: 9, Shape: maze.LShape{Arm: 1}, DoorPlan: maze.DoorPlan{DoorSides: []string{"right"}}}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	// The middle of the top side of the second den is masked, so its door moves one cell aside.
+	if err := m.AddDen(maze.Den{X: 27, Y: 11, Width: 9, Height: 9, Shape: maze.Circle{}, DoorPlan: maze.DoorPlan{DoorSides: []string{"top"}}}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	mask, err := maze.NewMask(m.Width(), m.Height())
+	if err != nil {
+		t.Fatalf("Failed to create mask: %v", err)
+	}
+	mask.SetBlocked(31, 9, true)
+	if err := m.SetMask(mask); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	doors := m.Doors()
+	if len(doors) != 2 || doors[0] != (maze.Point{X: 16, Y: 15}) || doors[1] != (maze.Point{X: 29, Y: 10}) {
+		t.Errorf("Expected doors at {16, 15} and {29, 10}, got %v", doors)
+	}
+}
+
+func TestDenShapeErrors(t *testing.T) {
+	m, err := maze.New(41, 31, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	testCases := []struct {
+		name  string
+		shape maze.DenShape
+	}{
+		{"Empty", maze.NewCellSet()},
+		{"Disconnected", maze.NewCellSet(maze.Point{X: 0, Y: 0}, maze.Point{X: 2, Y: 2})},
+		{"Diagonal only", maze.NewCellSet(maze.Point{X: 0, Y: 0}, maze.Point{X: 1, Y: 1})},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := m.AddDen(maze.Den{X: 5, Y: 5, Width: 5, Height: 5, Shape: tc.shape}); err == nil {
+				t.Error("Expected error, but got nil")
+			}
+		})
+	}
+
+	if err := m.SetDenShape(0, maze.Circle{}); err == nil {
+		t.Error("Expected error for a den that does not exist, but got nil")
+	}
+}
+
+func TestSetDenShape(t *testing.T) {
+	m, err := maze.New(41, 31, 13, 13)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetDenShape(0, maze.Cross{}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.IsInsideDen(maze.Point{X: m.DenStartX(), Y: m.DenStartY()}) {
+		t.Error("Expected the corner of the cross-shaped den to be outside it")
+	}
+	if err := m.Generate(2, nil, nil, nil, "bottom", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	checkPerfectMaze(t, m)
+
+	d, err := maze.ParseDen("9x9/circle@3,3:top")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if !reflect.DeepEqual(d, maze.Den{X: 3, Y: 3, Width: 9, Height: 9, Shape: maze.Circle{}, DoorPlan: maze.DoorPlan{DoorSides: []string{"top"}}}) {
+		t.Errorf("Unexpected den %+v", d)
+	}
+	if _, err := maze.ParseDen("9x9/hexagon"); err == nil {
+		t.Error("Expected error for an unknown shape, but got nil")
+	}
+}
+
+func TestCircleRadius(t *testing.T) {
+	m, err := maze.New(41, 31, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	// A den of 9x9 points has 5x5 cells, which fits a radius of at most 2.
+	if err := m.AddDen(maze.Den{X: 15, Y: 11, Width: 9, Height: 9, Shape: maze.Circle{Radius: 3}}); err == nil {
+		t.Error("Expected error for a radius larger than the den, but got nil")
+	}
+	if err := m.AddDen(maze.Den{X: 15, Y: 11, Width: 9, Height: 9, Shape: maze.Circle{Radius: 1}}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	// The disc of radius 1 covers the 3x3 cells around the center, but not the
+	// middle of the den's sides that the inscribed circle reaches.
+	if !m.IsInsideDen(maze.Point{X: 17, Y: 13}) || m.IsInsideDen(maze.Point{X: 19, Y: 11}) || m.IsInsideDen(maze.Point{X: 15, Y: 15}) {
+		t.Error("Expected a circle of radius 1 to cover only the cells around the den's center")
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	checkPerfectMaze(t, m)
+
+	for spec, want := range map[string]maze.Den{
+		"9x9/circle:r=1@3,3:top": {X: 3, Y: 3, Width: 9, Height: 9, Shape: maze.Circle{Radius: 1}, DoorPlan: maze.DoorPlan{DoorSides: []string{"top"}}},
+		"9x9/circle:r=2":         {Width: 9, Height: 9, Shape: maze.Circle{Radius: 2}, Auto: true},
+	} {
+		d, err := maze.ParseDen(spec)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, but got %v", spec, err)
+		}
+		if !reflect.DeepEqual(d, want) {
+			t.Errorf("Expected %q to parse as %+v, got %+v", spec, want, d)
+		}
+	}
+	for _, spec := range []string{"9x9/circle:r=0", "9x9/circle:d=2", "9x9/cross:r=1"} {
+		if _, err := maze.ParseDen(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package maze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultAttempts is the number of seeds GenerateMatching tries when the
+// constraints do not say otherwise.
+const DefaultAttempts = 1000
+
+// Metrics describes how hard a generated maze is.
+type Metrics struct {
+	// SolutionLength is the number of steps from the Start to the End.
+	SolutionLength int
+	// DeadEnds is the number of cells outside the dens with a single exit.
+	DeadEnds int
+	// Junctions is the number of cells outside the dens with three or four exits.
+	Junctions int
+	// Turns is the number of changes of direction on the way from the Start to the End.
+	Turns int
+}
+
+// Metrics measures the maze as it was last generated. A maze without a
+// route from its Start to its End has a SolutionLength and Turns of zero.
+func (m *Maze) Metrics() Metrics {
+	var mt Metrics
+	for _, c := range m.generationCells() {
+		switch {
+		case m.isDeadEnd(c):
+			mt.DeadEnds++
+		case m.grid[c.Y][c.X] != Wall && m.exits(c) >= 3:
+			mt.Junctions++
+		}
+	}
+
+	path, found := m.Solve()
+	if !found {
+		return mt
+	}
+	mt.SolutionLength = len(path) - 1
+	for i := 2; i < len(path); i++ {
+		if m.direction(path[i-2], path[i-1]) != m.direction(path[i-1], path[i]) {
+			mt.Turns++
+		}
+	}
+	return mt
+}
+
+// Range is an inclusive range of values. Without HasMax it is open at the
+// top, so the zero value accepts any value.
+type Range struct {
+	Min, Max int
+	// HasMax makes Max the upper bound of the range.
+	HasMax bool
+}
+
+// ParseRange parses a range given as "min-max", "min-", "-max" or a single
+// value, where "min-" is open at the top and a single value n is "n-n".
+func ParseRange(spec string) (Range, error) {
+	spec = strings.TrimSpace(spec)
+	bound := func(s string) (int, error) {
+		if s = strings.TrimSpace(s); s == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid range %q: %q is not a non-negative integer", spec, s)
+		}
+		return n, nil
+	}
+
+	lo, hi, isRange := strings.Cut(spec, "-")
+	var r Range
+	var err error
+	if r.Min, err = bound(lo); err != nil {
+		return Range{}, err
+	}
+	switch {
+	case !isRange:
+		r.Max, r.HasMax = r.Min, true
+	case strings.TrimSpace(hi) != "":
+		if r.Max, err = bound(hi); err != nil {
+			return Range{}, err
+		}
+		r.HasMax = true
+	}
+	return r, r.check()
+}
+
+// check validates a range.
+func (r Range) check() error {
+	if r.Min < 0 || r.Max < 0 {
+		return fmt.Errorf("range bounds must not be negative")
+	}
+	if !r.HasMax && r.Max != 0 {
+		return fmt.Errorf("range maximum (%d) is set without HasMax", r.Max)
+	}
+	if r.HasMax && r.Max < r.Min {
+		return fmt.Errorf("range maximum (%d) is less than its minimum (%d)", r.Max, r.Min)
+	}
+	return nil
+}
+
+// Contains reports whether n is within the range.
+func (r Range) Contains(n int) bool {
+	return n >= r.Min && (!r.HasMax || n <= r.Max)
+}
+
+// Constraints are the metrics a maze generated by GenerateMatching must meet.
+// The zero value of each range accepts any value.
+type Constraints struct {
+	SolutionLength Range
+	DeadEnds       Range
+	Junctions      Range
+	Turns          Range
+	// MaxAttempts is the number of seeds to try. Zero tries DefaultAttempts.
+	MaxAttempts int
+}
+
+// Met reports whether a maze with the given metrics meets the constraints.
+func (c Constraints) Met(mt Metrics) bool {
+	return c.SolutionLength.Contains(mt.SolutionLength) && c.DeadEnds.Contains(mt.DeadEnds) &&
+		c.Junctions.Contains(mt.Junctions) && c.Turns.Contains(mt.Turns)
+}
+
+// check validates the constraints.
+func (c Constraints) check() error {
+	for _, r := range []Range{c.SolutionLength, c.DeadEnds, c.Junctions, c.Turns} {
+		if err := r.check(); err != nil {
+			return err
+		}
+	}
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("number of attempts must not be negative")
+	}
+	return nil
+}
+
+// GenerateMatching generates the maze with the seeds baseSeed, baseSeed+1 and
+// so on until it meets the constraints, and returns the seed that did. The
+// other arguments are passed on to Generate, so calling Generate with the
+// returned seed and the same arguments reproduces the maze. If no seed meets
+// the constraints within the allowed attempts, the maze is left as the last
+// seed generated it and an error is returned.
+func (m *Maze) GenerateMatching(c Constraints, baseSeed int64, start, end *Point, door *Point, doorSide string, bias float64) (int64, error) {
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+	attempts := c.MaxAttempts
+	if attempts == 0 {
+		attempts = DefaultAttempts
+	}
+
+	for i := 0; i < attempts; i++ {
+		seed := baseSeed + int64(i)
+		if err := m.Generate(seed, start, end, door, doorSide, bias); err != nil {
+			return 0, err
+		}
+		if c.Met(m.Metrics()) {
+			return seed, nil
+		}
+	}
+	return 0, fmt.Errorf("no maze meets the constraints within %d attempts from seed %d", attempts, baseSeed)
+}
@@ -0,0 +1,160 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestMetrics(t *testing.T) {
+	m, err := maze.New(41, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	mt := m.Metrics()
+
+	path, _ := m.Solve()
+	if mt.SolutionLength != len(path)-1 {
+		t.Errorf("Expected a solution length of %d, got %d", len(path)-1, mt.SolutionLength)
+	}
+	turns := 0
+	for i := 2; i < len(path); i++ {
+		if path[i].X-path[i-1].X != path[i-1].X-path[i-2].X || path[i].Y-path[i-1].Y != path[i-1].Y-path[i-2].Y {
+			turns++
+		}
+	}
+	if mt.Turns != turns {
+		t.Errorf("Expected %d turns, got %d", turns, mt.Turns)
+	}
+
+	// In a perfect maze every cell is a node of a tree, so the number of dead
+	// ends exceeds the number of junctions by two plus the extra branches of
+	// four-way junctions.
+	fourWay := 0
+	for y := 1; y < m.Height()-1; y += 2 {
+		for x := 1; x < m.Width()-1; x += 2 {
+			exits := 0
+			for _, d := range []maze.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}} {
+				if c, _ := m.Cell(x+d.X, y+d.Y); c != maze.Wall {
+					exits++
+				}
+			}
+			if exits == 4 {
+				fourWay++
+			}
+		}
+	}
+	if mt.DeadEnds != countDeadEnds(m) {
+		t.Errorf("Expected %d dead ends, got %d", countDeadEnds(m), mt.DeadEnds)
+	}
+	if mt.DeadEnds != mt.Junctions+fourWay+2 {
+		t.Errorf("Expected %d dead ends for %d junctions, got %d", mt.Junctions+fourWay+2, mt.Junctions, mt.DeadEnds)
+	}
+
+	if err := m.SetBraid(1); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if mt := m.Metrics(); mt.DeadEnds != 0 {
+		t.Errorf("Expected no dead ends in a braided maze, got %d", mt.DeadEnds)
+	}
+}
+
+func TestGenerateMatching(t *testing.T) {
+	m, err := maze.New(41, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	c := maze.Constraints{
+		SolutionLength: maze.Range{Min: 150},
+		Turns:          maze.Range{Max: 60, HasMax: true},
+	}
+	seed, err := m.GenerateMatching(c, 100, nil, nil, nil, "", 0.5)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if seed < 100 {
+		t.Errorf("Expected a seed from 100 on, got %d", seed)
+	}
+	found := m.Metrics()
+	if !c.Met(found) {
+		t.Errorf("Expected the maze to meet the constraints, got %+v", found)
+	}
+	grid := gridString(m)
+
+	// The seed reproduces the maze with Generate alone.
+	if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if gridString(m) != grid || m.Metrics() != found {
+		t.Error("Expected Generate with the winning seed to reproduce the maze")
+	}
+
+	// The search is deterministic.
+	again, err := m.GenerateMatching(c, 100, nil, nil, nil, "", 0.5)
+	if err != nil || again != seed {
+		t.Errorf("Expected seed %d again, got %d (%v)", seed, again, err)
+	}
+
+	impossible := maze.Constraints{SolutionLength: maze.Range{Min: 10000}, MaxAttempts: 5}
+	if _, err := m.GenerateMatching(impossible, 1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error for constraints no maze can meet, but got nil")
+	}
+	invalid := maze.Constraints{DeadEnds: maze.Range{Min: 10, Max: 5, HasMax: true}}
+	if _, err := m.GenerateMatching(invalid, 1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error for an empty range, but got nil")
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected maze.Range
+	}{
+		{"40-80", maze.Range{Min: 40, Max: 80, HasMax: true}},
+		{"40-", maze.Range{Min: 40}},
+		{"-80", maze.Range{Max: 80, HasMax: true}},
+		{" 12 ", maze.Range{Min: 12, Max: 12, HasMax: true}},
+		{"0", maze.Range{HasMax: true}},
+		{"0-0", maze.Range{HasMax: true}},
+	}
+	for _, tc := range testCases {
+		r, err := maze.ParseRange(tc.spec)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, but got %v", tc.spec, err)
+		}
+		if r != tc.expected {
+			t.Errorf("ParseRange(%q) = %+v; want %+v", tc.spec, r, tc.expected)
+		}
+	}
+	for _, spec := range []string{"a-5", "5-b", "80-40", "3-0", "1-2-3"} {
+		if _, err := maze.ParseRange(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+}
+
+func TestRangeZero(t *testing.T) {
+	none, err := maze.ParseRange("0")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	m, err := maze.New(21, 11, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if n := m.Metrics().DeadEnds; n == 0 || none.Contains(n) {
+		t.Errorf("Expected the range 0 to reject a maze with %d dead ends", n)
+	}
+	if !none.Contains(0) {
+		t.Errorf("Expected the range 0 to accept 0")
+	}
+}
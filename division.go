@@ -0,0 +1,135 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Division carves the maze with recursive division.
+// Unlike the other generators it starts from an open grid and adds walls:
+// every chamber is split in two by a straight wall with a single gap, and the
+// halves are split again until they get too small. This produces long straight
+// walls with an architectural look. The start point and the maze bias are ignored.
+type Division struct {
+	// MinChamber is the size, in cells, that a chamber must reach in a
+	// direction before it is no longer split across that direction.
+	// Values above 1 leave open rooms; values below 1 are treated as 1,
+	// which divides the maze down to single-cell corridors.
+	MinChamber int
+	// Preference skews the orientation of the dividing walls, from -1
+	// (always vertical walls, if possible) to 1 (always horizontal walls).
+	// At 0 the orientation follows the shape of each chamber.
+	Preference float64
+}
+
+// newDivision builds a Division from the "min" and "pref" options.
+func newDivision(opts options) (Generator, error) {
+	min, err := opts.int("min", 1)
+	if err != nil {
+		return nil, err
+	}
+	pref, err := opts.float("pref", 0)
+	if err != nil {
+		return nil, err
+	}
+	if pref < -1 || pref > 1 {
+		return nil, fmt.Errorf("option pref must be between -1 and 1")
+	}
+	return Division{MinChamber: min, Preference: pref}, opts.check()
+}
+
+// chamber is a rectangle of cells, measured in cells rather than grid points.
+type chamber struct {
+	x, y, w, h int
+}
+
+// Carve implements Generator.
+func (d Division) Carve(m *Maze, r *rand.Rand, start Point) error {
+	minSize := max(d.MinChamber, 1)
+
+	// Open every passage that the den and the mask allow, leaving a single open space.
+	for _, c := range m.generationCells() {
+		m.grid[c.Y][c.X] = Path
+		for _, next := range []Point{{X: c.X + 2, Y: c.Y}, {X: c.X, Y: c.Y + 2}} {
+			if m.canCarve(c, next) {
+				m.carvePassage(c, next)
+			}
+		}
+	}
+
+	cell := func(i, j int) Point { return Point{X: 2*i + 1, Y: 2*j + 1} }
+	chambers := []chamber{{w: (m.width - 1) / 2, h: (m.height - 1) / 2}}
+	for len(chambers) > 0 {
+		c := chambers[len(chambers)-1]
+		chambers = chambers[:len(chambers)-1]
+
+		canSplitH, canSplitV := c.h >= 2*minSize, c.w >= 2*minSize
+		if !canSplitH && !canSplitV {
+			continue // Too small to divide: the chamber stays a room.
+		}
+		horizontal := canSplitH
+		if canSplitH && canSplitV {
+			horizontal = r.Float64() < odds(d.horizontalProbability(c), m.weights.horizontal(), m.weights.vertical())
+		}
+
+		// Split after k cells and wall off the boundary, leaving one gap.
+		var walls [][2]Point
+		if horizontal {
+			k := minSize + r.Intn(c.h-2*minSize+1)
+			for i := c.x; i < c.x+c.w; i++ {
+				walls = append(walls, [2]Point{cell(i, c.y+k-1), cell(i, c.y+k)})
+			}
+			chambers = append(chambers, chamber{c.x, c.y, c.w, k}, chamber{c.x, c.y + k, c.w, c.h - k})
+		} else {
+			k := minSize + r.Intn(c.w-2*minSize+1)
+			for j := c.y; j < c.y+c.h; j++ {
+				walls = append(walls, [2]Point{cell(c.x+k-1, j), cell(c.x+k, j)})
+			}
+			chambers = append(chambers, chamber{c.x, c.y, k, c.h}, chamber{c.x + k, c.y, c.w - k, c.h})
+		}
+		m.buildWall(r, walls)
+	}
+
+	// Open the pillars in the middle of rooms, so that rooms are truly empty.
+	for y := 2; y < m.height-2; y += 2 {
+		for x := 2; x < m.width-2; x += 2 {
+			if m.grid[y-1][x] == Path && m.grid[y+1][x] == Path && m.grid[y][x-1] == Path && m.grid[y][x+1] == Path && !m.IsMasked(Point{X: x, Y: y}) {
+				m.grid[y][x] = Path
+			}
+		}
+	}
+
+	// A wall that runs into the den or the mask may cut a chamber in two,
+	// so join any regions left apart.
+	m.connectComponents(r)
+	return nil
+}
+
+// horizontalProbability returns the chance that chamber c is split by a horizontal wall.
+func (d Division) horizontalProbability(c chamber) float64 {
+	// Taller chambers are split horizontally more often, wider ones vertically.
+	p := float64(c.h) / float64(c.w+c.h)
+	if d.Preference > 0 {
+		return p + d.Preference*(1-p)
+	}
+	return p * (1 + d.Preference)
+}
+
+// buildWall closes the passages between the given pairs of cells, except for one
+// randomly chosen gap. Passages that the den or the mask block anyway are not
+// candidates for the gap.
+func (m *Maze) buildWall(r *rand.Rand, walls [][2]Point) {
+	var gaps []int
+	for i, w := range walls {
+		if !m.canCarve(w[0], w[1]) {
+			continue
+		}
+		wall := m.wallBetween(w[0], w[1])
+		m.grid[wall.Y][wall.X] = Wall
+		gaps = append(gaps, i)
+	}
+	if len(gaps) > 0 {
+		w := walls[gaps[r.Intn(len(gaps))]]
+		m.carvePassage(w[0], w[1])
+	}
+}
@@ -0,0 +1,88 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestDivision(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		m, err := maze.New(41, 21, 11, 7)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.Division{}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+	}
+
+	checkReproducible(t, maze.Division{MinChamber: 2, Preference: 0.5})
+}
+
+func TestDivisionPreference(t *testing.T) {
+	m, err := maze.New(21, 11, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetGenerator(maze.Division{Preference: 1}); err != nil {
+		t.Fatalf("Failed to set generator: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	// Only horizontal walls are drawn while that is possible, so every row of cells is one long corridor.
+	for y := 1; y < m.Height()-1; y += 2 {
+		for x := 1; x < m.Width()-1; x++ {
+			if cell, _ := m.Cell(x, y); cell == maze.Wall {
+				t.Fatalf("Expected row %d to be an open corridor, got a wall at x=%d:\n%s", y, x, gridString(m))
+			}
+		}
+	}
+}
+
+func TestDivisionRooms(t *testing.T) {
+	m, err := maze.New(41, 21, 7, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	g, err := maze.ParseGenerator("division:min=3")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if g != (maze.Division{MinChamber: 3}) {
+		t.Errorf("Expected Division{MinChamber: 3}, got %+v", g)
+	}
+	if err := m.SetGenerator(g); err != nil {
+		t.Fatalf("Failed to set generator: %v", err)
+	}
+	if err := m.Generate(4, nil, nil, nil, "", 0); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	// Rooms are at least 3 cells wide, so some pillar between cells must be open.
+	pillars := 0
+	for y := 2; y < m.Height()-1; y += 2 {
+		for x := 2; x < m.Width()-1; x += 2 {
+			if cell, _ := m.Cell(x, y); cell != maze.Wall {
+				pillars++
+			}
+		}
+	}
+	if pillars == 0 {
+		t.Errorf("Expected open rooms, got\n%s", gridString(m))
+	}
+	if _, found := m.Solve(); !found {
+		t.Error("Generated maze should be solvable, but it is not")
+	}
+
+	for _, spec := range []string{"division:min=x", "division:pref=2", "division:size=3", "division:min"} {
+		if _, err := maze.ParseGenerator(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+}
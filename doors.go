@@ -0,0 +1,305 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+// DoorPolicy decides where the doors of a den go when they are not given explicitly.
+type DoorPolicy int
+
+const (
+	// RandomDoors places every door at a random wall between the den and the maze.
+	RandomDoors DoorPolicy = iota
+	// DoorPerSide places the doors in the middle of the den's sides, in the
+	// order top, right, bottom, left, so a den has at most four of them.
+	DoorPerSide
+	// SpreadDoors spaces the doors evenly around the den, starting at a random one.
+	SpreadDoors
+)
+
+// doorPolicyNames maps the spellings accepted by ParseDoorPolicy to policies.
+var doorPolicyNames = map[string]DoorPolicy{"random": RandomDoors, "side": DoorPerSide, "spread": SpreadDoors}
+
+// ParseDoorPolicy returns the door policy with the given name: random, side or spread.
+func ParseDoorPolicy(name string) (DoorPolicy, error) {
+	p, ok := doorPolicyNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("invalid door policy: %q. use 'random', 'side', or 'spread'", name)
+	}
+	return p, nil
+}
+
+// DoorPlan describes the doors of a den.
+type DoorPlan struct {
+	// DoorCount is the number of doors. Zero opens one door for every side
+	// and point given below, or a single door if none are.
+	DoorCount int
+	// DoorPolicy places the doors that DoorSides and DoorPoints leave over.
+	DoorPolicy DoorPolicy
+	// DoorGap is the least number of cells between two doors of the den,
+	// measured along its wall.
+	DoorGap int
+	// DoorSides puts a door in the middle of each of the given sides of the
+	// den: "top", "bottom", "left" or "right". If the den's shape leaves the
+	// middle unreachable, the door goes as close to it as possible.
+	DoorSides []string
+	// DoorPoints puts a door at each of the given wall points next to the den.
+	DoorPoints []Point
+}
+
+// check validates a door plan before generation.
+func (p DoorPlan) check() error {
+	if p.DoorCount < 0 {
+		return fmt.Errorf("door count must not be negative")
+	}
+	if p.DoorGap < 0 {
+		return fmt.Errorf("door gap must not be negative")
+	}
+	if p.DoorPolicy < RandomDoors || p.DoorPolicy > SpreadDoors {
+		return fmt.Errorf("invalid door policy: %d", p.DoorPolicy)
+	}
+	seen := make(map[string]bool)
+	for _, side := range p.DoorSides {
+		if !validDoorSide(side) {
+			return fmt.Errorf("invalid door side: %s. use 'top', 'bottom', 'left', or 'right'", side)
+		}
+		if seen[side] {
+			return fmt.Errorf("door side %s is given twice", side)
+		}
+		seen[side] = true
+	}
+	if explicit := len(p.DoorSides) + len(p.DoorPoints); p.DoorCount > 0 && p.DoorCount < explicit {
+		return fmt.Errorf("door count (%d) is less than the %d doors given", p.DoorCount, explicit)
+	}
+	if p.DoorPolicy == DoorPerSide && p.DoorCount > len(p.DoorPoints)+4 {
+		return fmt.Errorf("a den cannot have %d doors with one per side", p.DoorCount)
+	}
+	return nil
+}
+
+// SetDoorPlan changes the door plan of the den with the given index in Dens,
+// for example to give the central den created by New several doors.
+func (m *Maze) SetDoorPlan(i int, p DoorPlan) error {
+	if i < 0 || i >= len(m.dens) {
+		return fmt.Errorf("den %d does not exist", i)
+	}
+	if err := p.check(); err != nil {
+		return err
+	}
+	m.dens[i].DoorPlan = p
+	return nil
+}
+
+// openDoors opens the doors of a den: first those at the plan's sides and points,
+// then as many more as the plan asks for, placed by its policy.
+func (m *Maze) openDoors(den int, plan DoorPlan, r *rand.Rand) error {
+	for _, side := range plan.DoorSides {
+		if err := m.connectDenAtSide(den, side); err != nil {
+			return err
+		}
+	}
+	for _, p := range plan.DoorPoints {
+		if err := m.connectDenAtPoint(den, p); err != nil {
+			return err
+		}
+	}
+
+	explicit := len(plan.DoorSides) + len(plan.DoorPoints)
+	count := plan.DoorCount
+	if count == 0 {
+		count = max(explicit, 1)
+	}
+
+	var err error
+	switch n := count - explicit; {
+	case n == 0:
+	case plan.DoorPolicy == DoorPerSide:
+		err = m.connectSideDoors(den, n, plan.DoorSides)
+	case plan.DoorPolicy == SpreadDoors:
+		err = m.connectSpreadDoors(den, n, plan.DoorGap, r)
+	default:
+		err = m.connectRandomDenDoor(den, n, plan.DoorGap, r)
+	}
+	if err != nil {
+		return err
+	}
+	return m.checkDoorGap(den, plan.DoorGap)
+}
+
+// connectSideDoors opens n doors in the middle of the sides of a den that do
+// not have a door yet, in the order top, right, bottom, left.
+func (m *Maze) connectSideDoors(den, n int, used []string) error {
+	for _, side := range []string{"top", "right", "bottom", "left"} {
+		if n == 0 {
+			return nil
+		}
+		taken := false
+		for _, u := range used {
+			taken = taken || u == side
+		}
+		if taken {
+			continue
+		}
+		if err := m.connectDenAtSide(den, side); err != nil {
+			return err
+		}
+		n--
+	}
+	if n > 0 {
+		return fmt.Errorf("not enough free sides for %d more doors", n)
+	}
+	return nil
+}
+
+// connectSpreadDoors opens n doors evenly spaced along the wall around a
+// den. Starting at a possible door, each door goes to the possible door
+// nearest its even share of the wall that is at least gap cells from the
+// others. The start is tried at every possible door in turn, from a random
+// one, until all n doors fit.
+func (m *Maze) connectSpreadDoors(den, n, gap int, r *rand.Rand) error {
+	candidates := m.doorCandidates(den, gap)
+	if len(candidates) < n {
+		return fmt.Errorf("cannot place %d doors: the den has room for only %d", n, len(candidates))
+	}
+	positions, length := m.wallPositions(den, candidates[0])
+
+	offset := r.Intn(len(candidates))
+	for i := range candidates {
+		first := candidates[(offset+i)%len(candidates)]
+		doors := []Point{first}
+		var distances []map[Point]int
+		for len(doors) < n {
+			if gap > 1 {
+				distances = append(distances, m.wallDistances(den, doors[len(doors)-1]))
+			}
+			target := positions[first] + len(doors)*length/n
+			door, best := Point{}, -1
+			for _, c := range candidates {
+				pos, ok := positions[c]
+				if !ok || slices.Contains(doors, c) || !farEnough(distances, c, gap) {
+					continue
+				}
+				off := abs(pos-target) % length
+				if off = min(off, length-off); best < 0 || off < best {
+					door, best = c, off
+				}
+			}
+			if best < 0 {
+				break
+			}
+			doors = append(doors, door)
+		}
+		if len(doors) < n {
+			continue
+		}
+		for _, door := range doors {
+			m.grid[door.Y][door.X] = Path
+			m.doors[den] = append(m.doors[den], door)
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot place %d doors at least %d cells apart", len(m.doors[den])+n, gap)
+}
+
+// wallPositions returns the position of every point of the wall around a den,
+// in grid steps along the wall from the point from, and the length of the
+// wall. Positions grow in one direction around the den.
+func (m *Maze) wallPositions(den int, from Point) (map[Point]int, int) {
+	distances := m.wallDistances(den, from)
+	// A neighbor on the wall fixes the direction: the points that are closer
+	// to it than to from lie ahead.
+	ahead := map[Point]int{}
+	for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		next := Point{X: from.X + dir.X, Y: from.Y + dir.Y}
+		if distances[next] == 1 {
+			ahead = m.wallDistances(den, next)
+			break
+		}
+	}
+	// The wall is a loop, so the farthest point is halfway round, or there
+	// are two farthest points if the length is odd.
+	farthest, count := 0, 0
+	for _, d := range distances {
+		if d > farthest {
+			farthest, count = d, 0
+		}
+		if d == farthest {
+			count++
+		}
+	}
+	length := max(2*farthest+min(count, 2)-1, 1)
+
+	positions := make(map[Point]int, len(distances))
+	for p, d := range distances {
+		if a, ok := ahead[p]; (ok && a < d) || d == 0 {
+			positions[p] = d
+		} else {
+			positions[p] = length - d
+		}
+	}
+	return positions, length
+}
+
+// checkDoorGap verifies that the doors of a den are at least gap cells apart along its wall.
+func (m *Maze) checkDoorGap(den, gap int) error {
+	if gap <= 1 {
+		return nil
+	}
+	doors := m.doors[den]
+	for i, door := range doors {
+		distances := []map[Point]int{m.wallDistances(den, door)}
+		for _, other := range doors[i+1:] {
+			if !farEnough(distances, other, gap) {
+				return fmt.Errorf("doors at %+v and %+v are less than %d cells apart", door, other, gap)
+			}
+		}
+	}
+	return nil
+}
+
+// wallDistances returns the distance, in grid steps, from a door to every point
+// of the wall around a den that can be reached along the wall.
+func (m *Maze) wallDistances(den int, door Point) map[Point]int {
+	onWall := func(p Point) bool {
+		if p.X < 0 || p.X >= m.width || p.Y < 0 || p.Y >= m.height || m.IsInsideDen(p) {
+			return false
+		}
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if m.denAt(Point{X: p.X + dx, Y: p.Y + dy}) == den {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	distances := map[Point]int{door: 0}
+	queue := []Point{door}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			next := Point{X: current.X + dir.X, Y: current.Y + dir.Y}
+			if _, seen := distances[next]; !seen && onWall(next) {
+				distances[next] = distances[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distances
+}
+
+// farEnough reports whether a point is at least gap cells, that is 2*gap grid
+// steps, away from every door whose wall distances are given.
+func farEnough(distances []map[Point]int, p Point, gap int) bool {
+	for _, d := range distances {
+		if dist, ok := d[p]; ok && dist < 2*gap {
+			return false
+		}
+	}
+	return true
+}
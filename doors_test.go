@@ -0,0 +1,206 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// newDoorMaze creates a maze with a 9x5 central den at 15,13 and the given door plan.
+func newDoorMaze(t *testing.T, plan maze.DoorPlan) *maze.Maze {
+	t.Helper()
+	m, err := maze.New(41, 31, 9, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetDoorPlan(0, plan); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	return m
+}
+
+func TestRandomDoors(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		m := newDoorMaze(t, maze.DoorPlan{DoorCount: 3, DoorGap: 4})
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		doors := m.DenDoors(0)
+		if len(doors) != 3 {
+			t.Fatalf("Expected 3 doors, got %v", doors)
+		}
+		for i, a := range doors {
+			for _, b := range doors[i+1:] {
+				// Doors on the same side must be four cells apart; doors on
+				// different sides are farther apart along the wall anyway.
+				if (a.X == b.X && abs(a.Y-b.Y) < 8) || (a.Y == b.Y && abs(a.X-b.X) < 8) {
+					t.Errorf("Seed %d: doors %+v and %+v are too close", seed, a, b)
+				}
+			}
+		}
+		if m.Door() != doors[0] {
+			t.Errorf("Expected Door to return the first door, got %+v", m.Door())
+		}
+	}
+}
+
+func TestDoorPerSide(t *testing.T) {
+	m := newDoorMaze(t, maze.DoorPlan{DoorCount: 4, DoorPolicy: maze.DoorPerSide})
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	checkPerfectMaze(t, m)
+	expected := []maze.Point{{X: 19, Y: 12}, {X: 24, Y: 15}, {X: 19, Y: 18}, {X: 14, Y: 15}}
+	doors := m.DenDoors(0)
+	if len(doors) != len(expected) {
+		t.Fatalf("Expected doors %v, got %v", expected, doors)
+	}
+	for i := range expected {
+		if doors[i] != expected[i] {
+			t.Errorf("Expected door %d at %+v, got %+v", i, expected[i], doors[i])
+		}
+	}
+
+	// A side given explicitly, here through Generate, is not used twice.
+	m = newDoorMaze(t, maze.DoorPlan{DoorCount: 2, DoorPolicy: maze.DoorPerSide})
+	if err := m.Generate(1, nil, nil, nil, "top", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	doors = m.DenDoors(0)
+	if len(doors) != 2 || doors[0] != expected[0] || doors[1] != expected[1] {
+		t.Errorf("Expected doors on the top and right sides, got %v", doors)
+	}
+}
+
+func TestSpreadDoors(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		m := newDoorMaze(t, maze.DoorPlan{DoorCount: 4, DoorPolicy: maze.SpreadDoors})
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+
+		// Four evenly spread doors fall in different quarters around the den center.
+		quarters := make(map[[2]bool]bool)
+		for _, door := range m.DenDoors(0) {
+			quarters[[2]bool{door.X < 19, door.Y < 15}] = true
+		}
+		if len(quarters) < 3 {
+			t.Errorf("Seed %d: expected doors spread around the den, got %v", seed, m.DenDoors(0))
+		}
+	}
+}
+
+func TestSpreadDoorsGap(t *testing.T) {
+	// wallPos returns the position of a door along the 32 steps of the wall
+	// around the den, clockwise from its top-left corner at 14,12.
+	wallPos := func(p maze.Point) int {
+		switch {
+		case p.Y == 12:
+			return p.X - 14
+		case p.X == 24:
+			return 10 + p.Y - 12
+		case p.Y == 18:
+			return 16 + 24 - p.X
+		default:
+			return 26 + 18 - p.Y
+		}
+	}
+	// Four doors four cells apart take up the whole wall, so they only fit
+	// at exactly even spacing.
+	for seed := int64(1); seed <= 20; seed++ {
+		m := newDoorMaze(t, maze.DoorPlan{DoorCount: 4, DoorPolicy: maze.SpreadDoors, DoorGap: 4})
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Seed %d: expected no error, but got %v", seed, err)
+		}
+		checkPerfectMaze(t, m)
+		doors := m.DenDoors(0)
+		for i, a := range doors {
+			for _, b := range doors[i+1:] {
+				if d := abs(wallPos(a) - wallPos(b)); min(d, 32-d) < 8 {
+					t.Errorf("Seed %d: doors %+v and %+v are less than 4 cells apart along the wall", seed, a, b)
+				}
+			}
+		}
+	}
+}
+
+func TestExplicitDoors(t *testing.T) {
+	points := []maze.Point{{X: 17, Y: 12}, {X: 24, Y: 17}}
+	m := newDoorMaze(t, maze.DoorPlan{DoorSides: []string{"left"}, DoorPoints: points})
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	doors := m.DenDoors(0)
+	if len(doors) != 3 || doors[0] != (maze.Point{X: 14, Y: 15}) || doors[1] != points[0] || doors[2] != points[1] {
+		t.Errorf("Expected the left door followed by %v, got %v", points, doors)
+	}
+	checkPerfectMaze(t, m)
+
+	m = newDoorMaze(t, maze.DoorPlan{DoorCount: 3, DoorSides: []string{"bottom"}})
+	if err := m.Generate(4, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	doors = m.DenDoors(0)
+	if len(doors) != 3 || doors[0] != (maze.Point{X: 19, Y: 18}) {
+		t.Errorf("Expected the bottom door followed by two random doors, got %v", doors)
+	}
+}
+
+func TestDoorPlanErrors(t *testing.T) {
+	m, err := maze.New(41, 31, 9, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	testCases := []struct {
+		name string
+		plan maze.DoorPlan
+	}{
+		{"Negative count", maze.DoorPlan{DoorCount: -1}},
+		{"Negative gap", maze.DoorPlan{DoorGap: -2}},
+		{"Unknown policy", maze.DoorPlan{DoorPolicy: maze.DoorPolicy(7)}},
+		{"Invalid side", maze.DoorPlan{DoorSides: []string{"up"}}},
+		{"Repeated side", maze.DoorPlan{DoorSides: []string{"top", "top"}}},
+		{"Count below explicit doors", maze.DoorPlan{DoorCount: 1, DoorSides: []string{"top", "left"}}},
+		{"Too many per side", maze.DoorPlan{DoorCount: 5, DoorPolicy: maze.DoorPerSide}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := m.SetDoorPlan(0, tc.plan); err == nil {
+				t.Errorf("Expected error for plan %+v, but got nil", tc.plan)
+			}
+		})
+	}
+	if err := m.SetDoorPlan(1, maze.DoorPlan{}); err == nil {
+		t.Error("Expected error for a den that does not exist, but got nil")
+	}
+
+	// A 9x5 den has a wall of about 28 cells, too short for 6 doors 8 cells apart.
+	m = newDoorMaze(t, maze.DoorPlan{DoorCount: 6, DoorGap: 8})
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error when the doors do not fit the gap, but got nil")
+	}
+	m = newDoorMaze(t, maze.DoorPlan{DoorCount: 2, DoorGap: 8, DoorSides: []string{"top", "left"}})
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error when explicit doors break the gap, but got nil")
+	}
+}
+
+func TestParseDoorPolicy(t *testing.T) {
+	for name, expected := range map[string]maze.DoorPolicy{"random": maze.RandomDoors, "Side": maze.DoorPerSide, "spread": maze.SpreadDoors} {
+		p, err := maze.ParseDoorPolicy(name)
+		if err != nil || p != expected {
+			t.Errorf("ParseDoorPolicy(%q) = %v, %v; want %v", name, p, err, expected)
+		}
+	}
+	if _, err := maze.ParseDoorPolicy("even"); err == nil {
+		t.Error("Expected error for an unknown policy, but got nil")
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
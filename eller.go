@@ -0,0 +1,255 @@
+package maze
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Eller carves the maze with Eller's algorithm, one row of cells at a time.
+// Each row only needs to know which cells of the row above are connected, so the
+// same algorithm can also stream mazes of unbounded height; see EllerStream.
+// The direction weights decide how often cells are joined sideways rather
+// than downwards. The start point and the maze bias are ignored.
+type Eller struct{}
+
+// Carve implements Generator.
+func (Eller) Carve(m *Maze, r *rand.Rand, start Point) error {
+	cols, rows := (m.width-1)/2, (m.height-1)/2
+	cell := func(i, j int) Point { return Point{X: 2*i + 1, Y: 2*j + 1} }
+
+	row := newEllerRow(cols)
+	for j := 0; j < rows; j++ {
+		last := j == rows-1
+		row.fill(func(i int) bool { return !m.IsInsideDen(cell(i, j)) && !m.IsMasked(cell(i, j)) })
+		east := row.join(r, func(i int) bool { return m.canCarve(cell(i, j), cell(i+1, j)) }, last, m.weights)
+		for i, set := range row.sets {
+			if set != 0 {
+				p := cell(i, j)
+				m.grid[p.Y][p.X] = Path
+			}
+			if east[i] {
+				m.carvePassage(cell(i, j), cell(i+1, j))
+			}
+		}
+		if last {
+			break
+		}
+		south := row.descend(r, func(i int) bool { return m.canCarve(cell(i, j), cell(i, j+1)) }, m.weights)
+		for i, down := range south {
+			if down {
+				m.carvePassage(cell(i, j), cell(i, j+1))
+			}
+		}
+	}
+
+	// Sets that run into the den or the mask cannot always continue downwards,
+	// so join any regions they leave behind.
+	m.connectComponents(r)
+	return nil
+}
+
+// EllerStream generates a maze with Eller's algorithm and emits it one grid row
+// at a time, using memory proportional to the width only. The maze has no fixed
+// height: call NextRow for as many rows as needed and Close to finish it.
+// Streamed mazes have no den, no Start or End markers and no direction weights.
+type EllerStream struct {
+	width   int
+	r       *rand.Rand
+	row     *ellerRow
+	emit    func(row []Cell) error
+	started bool
+	closed  bool
+}
+
+// NewEllerStream creates a stream of maze rows of the given width, which is
+// adjusted to be odd like in New. Every generated grid row is passed to emit,
+// which must not retain the slice.
+func NewEllerStream(width int, seed int64, emit func(row []Cell) error) (*EllerStream, error) {
+	if width < 3 {
+		return nil, fmt.Errorf("width must be at least 3")
+	}
+	width = adjustToOdd(width)
+	return &EllerStream{
+		width: width,
+		r:     rand.New(rand.NewSource(seed)),
+		row:   newEllerRow((width - 1) / 2),
+		emit:  emit,
+	}, nil
+}
+
+// NextRow generates the next row of cells and emits it, followed by the wall
+// row beneath it. The top border is emitted before the first row.
+func (s *EllerStream) NextRow() error {
+	return s.step(false)
+}
+
+// Close generates the final row of cells, connecting everything that is still
+// apart, and emits it followed by the bottom border. The stream cannot be used afterwards.
+func (s *EllerStream) Close() error {
+	return s.step(true)
+}
+
+// step generates one row of cells and emits the grid rows it produces.
+func (s *EllerStream) step(last bool) error {
+	if s.closed {
+		return fmt.Errorf("eller stream is already closed")
+	}
+	line := make([]Cell, s.width)
+	walls := func() []Cell {
+		for i := range line {
+			line[i] = Wall
+		}
+		return line
+	}
+
+	if !s.started {
+		s.started = true
+		if err := s.emit(walls()); err != nil {
+			return err
+		}
+	}
+
+	always := func(int) bool { return true }
+	s.row.fill(always)
+	east := s.row.join(s.r, always, last, DirectionWeights{})
+	walls()
+	for i, joined := range east {
+		line[2*i+1] = Path
+		if joined {
+			line[2*i+2] = Path
+		}
+	}
+	if err := s.emit(line); err != nil {
+		return err
+	}
+
+	walls()
+	if last {
+		s.closed = true
+		return s.emit(line)
+	}
+	for i, down := range s.row.descend(s.r, always, DirectionWeights{}) {
+		if down {
+			line[2*i+1] = Path
+		}
+	}
+	return s.emit(line)
+}
+
+// StreamEller writes a maze generated with Eller's algorithm to w, one text line
+// per grid row, as the rows are produced. A height of zero or less streams rows
+// until writing to w fails; any other height must be at least 3.
+func StreamEller(w io.Writer, width, height int, seed int64) error {
+	if height > 0 && height < 3 {
+		return fmt.Errorf("height must be at least 3, or 0 to stream endlessly")
+	}
+	s, err := NewEllerStream(width, seed, func(row []Cell) error {
+		_, err := io.WriteString(w, string(row)+"\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if height <= 0 {
+		for {
+			if err := s.NextRow(); err != nil {
+				return err
+			}
+		}
+	}
+	for rows := (adjustToOdd(height) - 1) / 2; rows > 1; rows-- {
+		if err := s.NextRow(); err != nil {
+			return err
+		}
+	}
+	return s.Close()
+}
+
+// ellerRow is the state Eller's algorithm carries from one row of cells to the
+// next: the set each cell belongs to. Set 0 marks a cell that is not part of the maze.
+type ellerRow struct {
+	sets    []int
+	nextSet int
+}
+
+// newEllerRow creates the state for rows of the given number of cells.
+func newEllerRow(cols int) *ellerRow {
+	return &ellerRow{sets: make([]int, cols)}
+}
+
+// fill puts every open cell that was not reached from above into a new set of its own.
+func (e *ellerRow) fill(open func(i int) bool) {
+	for i := range e.sets {
+		switch {
+		case !open(i):
+			e.sets[i] = 0
+		case e.sets[i] == 0:
+			e.nextSet++
+			e.sets[i] = e.nextSet
+		}
+	}
+}
+
+// join randomly opens passages between neighboring cells of different sets and
+// reports which cells got a passage to the east. On the last row every such pair
+// is joined, so that the maze ends up connected.
+func (e *ellerRow) join(r *rand.Rand, canEast func(i int) bool, last bool, w DirectionWeights) []bool {
+	east := make([]bool, len(e.sets))
+	for i := 0; i+1 < len(e.sets); i++ {
+		a, b := e.sets[i], e.sets[i+1]
+		if a == 0 || b == 0 || a == b || !canEast(i) {
+			continue
+		}
+		if last || coin(r, w.horizontal(), w.vertical()) {
+			east[i] = true
+			for k := range e.sets {
+				if e.sets[k] == b {
+					e.sets[k] = a
+				}
+			}
+		}
+	}
+	return east
+}
+
+// descend randomly opens passages to the row below, at least one per set where
+// possible, reports which cells got one, and moves the state down to the next row.
+func (e *ellerRow) descend(r *rand.Rand, canSouth func(i int) bool, w DirectionWeights) []bool {
+	// Group the cells of each set in row order, so the result does not
+	// depend on map iteration order.
+	var order []int
+	members := make(map[int][]int)
+	for i, set := range e.sets {
+		if set == 0 || !canSouth(i) {
+			continue
+		}
+		if _, ok := members[set]; !ok {
+			order = append(order, set)
+		}
+		members[set] = append(members[set], i)
+	}
+
+	south := make([]bool, len(e.sets))
+	for _, set := range order {
+		cells := members[set]
+		down := false
+		for _, i := range cells {
+			if coin(r, w.vertical(), w.horizontal()) {
+				south[i] = true
+				down = true
+			}
+		}
+		if !down {
+			south[cells[r.Intn(len(cells))]] = true
+		}
+	}
+
+	for i := range e.sets {
+		if !south[i] {
+			e.sets[i] = 0
+		}
+	}
+	return south
+}
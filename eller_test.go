@@ -0,0 +1,99 @@
+package maze_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestEller(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		m, err := maze.New(41, 21, 11, 7)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.Eller{}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+	}
+
+	checkReproducible(t, maze.Eller{})
+}
+
+func TestEllerStream(t *testing.T) {
+	var rows []string
+	s, err := maze.NewEllerStream(20, 3, func(row []maze.Cell) error {
+		rows = append(rows, string(row))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	for i := 0; i < 9; i++ {
+		if err := s.NextRow(); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := s.NextRow(); err == nil {
+		t.Error("Expected error after Close, but got nil")
+	}
+
+	if len(rows) != 21 {
+		t.Fatalf("Expected 21 grid rows for 10 rows of cells, got %d", len(rows))
+	}
+	wall := strings.Repeat(string(maze.Wall), 21)
+	if rows[0] != wall || rows[len(rows)-1] != wall {
+		t.Errorf("Expected solid top and bottom borders, got\n%s", strings.Join(rows, "\n"))
+	}
+
+	// The streamed rows must form a spanning tree over all cells.
+	open := func(x, y int) bool { return []rune(rows[y])[x] != rune(maze.Wall) }
+	cells, passages := 0, 0
+	for y := 1; y < len(rows)-1; y += 2 {
+		for x := 1; x < 20; x += 2 {
+			if !open(x, y) {
+				t.Fatalf("Expected cell %d,%d to be carved", x, y)
+			}
+			cells++
+			if x+2 < 20 && open(x+1, y) {
+				passages++
+			}
+			if y+2 < len(rows)-1 && open(x, y+1) {
+				passages++
+			}
+		}
+	}
+	if passages != cells-1 {
+		t.Errorf("Expected a spanning tree with %d passages, got %d", cells-1, passages)
+	}
+}
+
+func TestStreamEller(t *testing.T) {
+	var buf bytes.Buffer
+	if err := maze.StreamEller(&buf, 15, 7, 1); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 7 {
+		t.Errorf("Expected 7 lines, got %d:\n%s", len(lines), buf.String())
+	}
+
+	if _, err := maze.NewEllerStream(1, 1, nil); err == nil {
+		t.Error("Expected error for a too narrow stream, but got nil")
+	}
+	for _, height := range []int{1, 2} {
+		buf.Reset()
+		if err := maze.StreamEller(&buf, 15, height, 1); err == nil || buf.Len() > 0 {
+			t.Errorf("Expected error and no rows for a stream of height %d, got %v and %q", height, err, buf.String())
+		}
+	}
+}
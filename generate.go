@@ -3,15 +3,26 @@ package maze
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 )
 
-// Generate creates the maze paths using an iterative randomized depth-first search.
+// Generate creates the maze paths using the maze's generator, which is an
+// iterative randomized depth-first search unless SetGenerator chose another.
 // It takes a seed for reproducibility, an optional start point, and a bias
 // that controls the straightness of corridors.
 func (m *Maze) Generate(seed int64, start, end *Point, door *Point, doorSide string, bias float64) error {
 	r := rand.New(rand.NewSource(seed))
 	var generationStart Point
 
+	// Start from a fresh grid so that Generate can be called repeatedly,
+	// with the automatic dens moved to their new places.
+	if err := m.placeAutoDens(r); err != nil {
+		return err
+	}
+	m.initializeGrid()
+	m.doors = nil
+	m.bias = bias
+
 	// 1. Validate user-provided start and end points.
 	if start != nil {
 		if err := m.validatePoint(*start, "start"); err != nil {
@@ -26,6 +37,29 @@ func (m *Maze) Generate(seed int64, start, end *Point, door *Point, doorSide str
 	if start != nil && end != nil && *start == *end {
 		return fmt.Errorf("start and end points cannot be the same")
 	}
+	if start != nil && m.denWithRole(StartDen) >= 0 {
+		return fmt.Errorf("start point cannot be given when a den holds the start")
+	}
+	if end != nil && m.denWithRole(EndDen) >= 0 {
+		return fmt.Errorf("end point cannot be given when a den holds the end")
+	}
+	if m.entrance != nil && (start != nil || m.denWithRole(StartDen) >= 0) {
+		return fmt.Errorf("maze with an entrance cannot have another start")
+	}
+	if m.exit != nil && (end != nil || m.denWithRole(EndDen) >= 0) {
+		return fmt.Errorf("maze with an exit cannot have another end")
+	}
+	if (m.entrance != nil || m.exit != nil) && m.placement.Strategy != LongestPath {
+		return fmt.Errorf("placement strategy %s cannot be combined with an entrance or exit", m.placement.Strategy)
+	}
+	for _, o := range []*Opening{m.entrance, m.exit} {
+		if o != nil && o.Side != "" && m.wrapsSide(o.Side) {
+			return fmt.Errorf("cannot open the %s border: it wraps around", o.Side)
+		}
+	}
+	if _, ok := m.Generator().(wrapCarver); !ok && m.wrap != NoWrap {
+		return fmt.Errorf("generator %s does not support wrapping mazes. use one of: %s", generatorName(m.Generator()), strings.Join(wrapGeneratorNames(), ", "))
+	}
 
 	// 2. Choose a starting point for the generation algorithm.
 	// Priority: user-specified start > user-specified end > random.
@@ -36,16 +70,8 @@ func (m *Maze) Generate(seed int64, start, end *Point, door *Point, doorSide str
 		generationStart = *end
 	} else {
 		// Both start and end are nil, so choose a random starting point.
-		// It must be on a path cell (odd coordinates) and not inside the den.
-		var potentialStarts []Point
-		for y := 1; y < m.height-1; y += 2 {
-			for x := 1; x < m.width-1; x += 2 {
-				p := Point{X: x, Y: y}
-				if !m.IsInsideDen(p) {
-					potentialStarts = append(potentialStarts, p)
-				}
-			}
-		}
+		// It must be on a path cell (odd coordinates), not inside the den and not masked.
+		potentialStarts := m.generationCells()
 		if len(potentialStarts) == 0 {
 			return fmt.Errorf("could not find a valid random starting point for generation; maze may be too small or constrained")
 		}
@@ -53,17 +79,20 @@ func (m *Maze) Generate(seed int64, start, end *Point, door *Point, doorSide str
 	}
 
 	// 3. Run the generation algorithm.
-	m.runDFS(r, generationStart, bias)
+	if err := m.Generator().Carve(m, r, generationStart); err != nil {
+		return err
+	}
 
-	// 4. If a den exists, create a single door to connect it to the maze.
+	// 4. If there are dens, create a single door to connect each of them to the maze.
 	if err := m.connectDen(r, door, doorSide); err != nil {
 		return err
 	}
 
-	// 5. Set the Start and End points for the maze.
-	m.placeStartAndEnd(generationStart, start, end)
+	// 5. Knock out walls at dead ends if the maze should have loops.
+	m.braided = m.braidMaze(r)
 
-	return nil
+	// 6. Set the Start and End points for the maze.
+	return m.placeStartAndEnd(r, generationStart, start, end)
 }
 
 // validatePoint checks if a point is a valid location for a start or end marker.
@@ -74,162 +103,358 @@ func (m *Maze) validatePoint(p Point, pointType string) error {
 	if m.IsInsideDen(p) {
 		return fmt.Errorf("invalid %s point: %+v. cannot be inside the den", pointType, p)
 	}
+	if m.IsMasked(p) {
+		return fmt.Errorf("invalid %s point: %+v. cannot be outside the mask", pointType, p)
+	}
 	return nil
 }
 
-// runDFS executes the iterative depth-first search algorithm to carve the maze paths.
-func (m *Maze) runDFS(r *rand.Rand, start Point, bias float64) {
-	var stack []Point
+// carveCells carves the maze from start with a generator written for any
+// cellGraph, on the square grid seen as one.
+func (m *Maze) carveCells(g graphCarver, r *rand.Rand, start Point) error {
+	m.grid[start.Y][start.X] = Path
+	s := squareGraph{m}
+	return g.carveGraph(s, r, s.index(start))
+}
 
-	current := start
-	m.grid[current.Y][current.X] = Path
-	stack = append(stack, current)
+// squareGraph is the cellGraph of the square grid of a Maze. Each cell is
+// numbered by its position in the grid, y*width+x, and passages are carved
+// into the grid, across the maze's joined borders, with its direction
+// weights and bias map.
+type squareGraph struct {
+	m *Maze
+}
 
-	for len(stack) > 0 {
-		current = stack[len(stack)-1]
+// index returns the number of the cell at p.
+func (s squareGraph) index(p Point) int {
+	return p.Y*s.m.width + p.X
+}
 
-		neighbors := m.findValidNeighbors(current)
+// point returns the cell with number c.
+func (s squareGraph) point(c int) Point {
+	return Point{X: c % s.m.width, Y: c / s.m.width}
+}
 
-		if len(neighbors) > 0 {
-			next := chooseBiasedNeighbor(neighbors, stack, bias, r)
+// size implements cellGraph.
+func (s squareGraph) size() int {
+	return s.m.width * s.m.height
+}
 
-			// Carve a path between the current cell and the neighbor
-			wallToRemove := Point{
-				X: current.X + (next.X-current.X)/2,
-				Y: current.Y + (next.Y-current.Y)/2,
-			}
-			m.grid[wallToRemove.Y][wallToRemove.X] = Path
-			m.grid[next.Y][next.X] = Path
+// cells implements cellGraph.
+func (s squareGraph) cells() []int {
+	var cells []int
+	for _, p := range s.m.generationCells() {
+		cells = append(cells, s.index(p))
+	}
+	return cells
+}
 
-			stack = append(stack, next)
-		} else {
-			// If no unvisited neighbors, backtrack by popping from the stack
-			stack = stack[:len(stack)-1]
-		}
+// neighbors implements cellGraph.
+func (s squareGraph) neighbors(c int) []int {
+	var neighbors []int
+	for _, p := range s.m.carvableNeighbors(s.point(c)) {
+		neighbors = append(neighbors, s.index(p))
+	}
+	return neighbors
+}
+
+// link implements cellGraph.
+func (s squareGraph) link(a, b int) {
+	s.m.carvePassage(s.point(a), s.point(b))
+}
+
+// straight implements cellGraph.
+func (s squareGraph) straight(prev, cur int) int {
+	from, to := s.point(prev), s.point(cur)
+	next := s.m.step(to, s.m.direction(from, to))
+	if !s.m.canCarve(to, next) {
+		return -1
+	}
+	return s.index(next)
+}
+
+// biasAt implements cellGraph with the maze's bias map.
+func (s squareGraph) biasAt(c int) float64 {
+	return s.m.biasAt(s.point(c))
+}
+
+// pick implements cellGraph with the maze's direction weights.
+func (s squareGraph) pick(r *rand.Rand, prev, cur int, neighbors []int) int {
+	var last Point
+	if prev >= 0 {
+		last = s.m.direction(s.point(prev), s.point(cur))
+	}
+	points := make([]Point, len(neighbors))
+	for i, n := range neighbors {
+		points[i] = s.point(n)
 	}
+	return s.index(s.m.pick(r, s.point(cur), last, points))
 }
 
-// findValidNeighbors finds all unvisited neighbors of a point that can be carved into.
-func (m *Maze) findValidNeighbors(p Point) []Point {
+// weighted implements cellGraph.
+func (s squareGraph) weighted() bool {
+	return !s.m.weights.neutral()
+}
+
+// weight implements cellGraph.
+func (s squareGraph) weight(from, to int) float64 {
+	return s.m.weights.weight(s.m.direction(s.point(from), s.point(to)), Point{})
+}
+
+// carvableNeighbors finds all neighbors of a point that a passage may connect it to,
+// whether or not they have been visited yet.
+func (m *Maze) carvableNeighbors(p Point) []Point {
 	var neighbors []Point
 	directions := []Point{{X: 0, Y: -2}, {X: 0, Y: 2}, {X: -2, Y: 0}, {X: 2, Y: 0}}
 
 	for _, dir := range directions {
-		next := Point{X: p.X + dir.X, Y: p.Y + dir.Y}
-
-		// Check if the neighbor is a valid, unvisited cell that doesn't breach the den.
-		if next.X > 0 && next.X < m.width-1 && next.Y > 0 && next.Y < m.height-1 && m.grid[next.Y][next.X] == Wall {
-			wallBetween := Point{X: p.X + dir.X/2, Y: p.Y + dir.Y/2}
-			if m.IsInsideDen(wallBetween) || m.IsAdjacentToDen(next) {
-				continue
-			}
+		next := m.step(p, dir)
+		if m.canCarve(p, next) {
 			neighbors = append(neighbors, next)
 		}
 	}
 	return neighbors
 }
 
-// chooseBiasedNeighbor selects a neighbor from a list, applying a bias to continue in a straight line.
-func chooseBiasedNeighbor(neighbors []Point, stack []Point, bias float64, r *rand.Rand) Point {
-	// Determine the last direction of travel.
-	var lastDirection Point
-	if len(stack) > 1 {
-		previous := stack[len(stack)-2]
-		current := stack[len(stack)-1]
-		lastDirection = Point{X: current.X - previous.X, Y: current.Y - previous.Y}
+// canCarve reports whether a passage may be opened between two neighboring cells
+// without leaving the maze, breaching the den or crossing the mask.
+func (m *Maze) canCarve(from, to Point) bool {
+	for _, p := range []Point{from, to} {
+		if p.X <= 0 || p.X >= m.width-1 || p.Y <= 0 || p.Y >= m.height-1 || m.IsInsideDen(p) || m.IsAdjacentToDen(p) || m.IsMasked(p) {
+			return false
+		}
 	}
+	wall := m.wallBetween(from, to)
+	return !m.IsInsideDen(wall) && !m.IsMasked(wall)
+}
 
-	// Check if moving straight is a valid option.
-	var straightOption *Point
-	for i := range neighbors {
-		n := neighbors[i]
-		current := stack[len(stack)-1]
-		if (n.X-current.X) == lastDirection.X && (n.Y-current.Y) == lastDirection.Y {
-			straightOption = &neighbors[i]
-			break
+// wallBetween returns the wall cell that separates two neighboring cells.
+func (m *Maze) wallBetween(from, to Point) Point {
+	dir := m.direction(from, to)
+	return Point{X: from.X + dir.X/2, Y: from.Y + dir.Y/2}
+}
+
+// carvePassage opens the wall between two neighboring cells and both cells themselves.
+func (m *Maze) carvePassage(from, to Point) {
+	wall := m.wallBetween(from, to)
+	m.grid[from.Y][from.X] = Path
+	m.openWall(wall)
+	m.grid[to.Y][to.X] = Path
+}
+
+// edge is a candidate passage between two neighboring cells.
+type edge struct {
+	from, to Point
+}
+
+// connectComponents joins all carved regions of the maze into one by opening
+// walls between cells of different regions in random order, as Kruskal's
+// algorithm would. Cells that were left uncarved are joined as well.
+// Generators that cannot guarantee connectivity around the den use it as a final pass.
+func (m *Maze) connectComponents(r *rand.Rand) {
+	cells := m.generationCells()
+	index := make(map[Point]int, len(cells))
+	for i, c := range cells {
+		index[c] = i
+	}
+
+	sets := newUnionFind(len(cells))
+	var edges []edge
+	for _, c := range cells {
+		for _, next := range []Point{m.step(c, Point{X: 2}), m.step(c, Point{Y: 2})} {
+			if _, ok := index[next]; !ok || !m.canCarve(c, next) {
+				continue
+			}
+			if wall := m.wallBetween(c, next); m.grid[wall.Y][wall.X] != Wall {
+				sets.union(index[c], index[next])
+			} else {
+				edges = append(edges, edge{from: c, to: next})
+			}
 		}
 	}
+	var weight func(e edge) float64
+	if !m.weights.neutral() {
+		weight = func(e edge) float64 { return m.weights.axis(m.direction(e.from, e.to)) }
+	}
+	shuffleEdges(r, edges, weight)
 
-	if straightOption != nil && r.Float64() < bias {
-		return *straightOption
+	for _, e := range edges {
+		if sets.union(index[e.from], index[e.to]) {
+			m.carvePassage(e.from, e.to)
+		}
 	}
+}
 
-	// Otherwise, pick a random neighbor from the available options.
-	return neighbors[r.Intn(len(neighbors))]
+// generationCells returns all cells (odd coordinates) outside the den and the mask
+// that a generator may carve, in row-major order.
+func (m *Maze) generationCells() []Point {
+	var cells []Point
+	for y := 1; y < m.height-1; y += 2 {
+		for x := 1; x < m.width-1; x += 2 {
+			p := Point{X: x, Y: y}
+			if !m.IsInsideDen(p) && !m.IsMasked(p) {
+				cells = append(cells, p)
+			}
+		}
+	}
+	return cells
 }
 
 // placeStartAndEnd determines and sets the Start and End points on the maze grid.
-func (m *Maze) placeStartAndEnd(generationStart Point, userStart, userEnd *Point) {
-	if userStart != nil {
+// A den that holds the Start or End puts it at its role cell, and the
+// automatic counterpart goes to the point farthest from the den's doors.
+// An entrance or exit puts it in the outer wall, as far along the paths from
+// the other end as its side and offset allow. Anything else follows the
+// maze's placement strategy, and the result is recorded for PlacementResult.
+func (m *Maze) placeStartAndEnd(r *rand.Rand, generationStart Point, userStart, userEnd *Point) error {
+	startDen, endDen := m.denWithRole(StartDen), m.denWithRole(EndDen)
+	for _, i := range []int{startDen, endDen} {
+		if i >= 0 && len(m.doors[i]) == 0 {
+			return fmt.Errorf("den at %d,%d has no door to reach its %s", m.dens[i].X, m.dens[i].Y, m.dens[i].Role.marker())
+		}
+	}
+
+	// A generator may leave a given point walled in, as a cave does, so
+	// tunnel it to the nearest path.
+	for _, p := range []*Point{userStart, userEnd} {
+		if p != nil && m.grid[p.Y][p.X] == Wall && !m.carvePathFrom([]Point{*p}, func(q Point) bool { return m.grid[q.Y][q.X] == Path }) {
+			return fmt.Errorf("point %+v cannot be connected to the maze", *p)
+		}
+	}
+
+	fixedStart, fixedEnd := userStart, userEnd
+	if startDen >= 0 {
+		p := m.dens[startDen].roleCell()
+		fixedStart = &p
+	}
+	if endDen >= 0 {
+		p := m.dens[endDen].roleCell()
+		fixedEnd = &p
+	}
+	m.placed = PlacementResult{Strategy: m.placement.Strategy}
+	startGiven := fixedStart != nil || m.entrance != nil && m.entrance.Offset > 0
+	endGiven := fixedEnd != nil || m.exit != nil && m.exit.Offset > 0
+	if startGiven && endGiven {
+		m.placed.Strategy = FixedPlacement
+	}
+
+	if m.placement.Strategy != LongestPath {
+		if err := m.placeWithStrategy(r, generationStart, fixedStart, fixedEnd); err != nil {
+			return err
+		}
+		return m.markStartAndEnd()
+	}
+
+	// An exit at a fixed offset is known before the Start, so the entrance
+	// can be placed away from it.
+	var exit *Point
+	if m.exit != nil && m.exit.Offset > 0 {
+		p, err := m.placeOpening(*m.exit, nil, generationStart)
+		if err != nil {
+			return fmt.Errorf("cannot place exit: %w", err)
+		}
+		exit = &p
+	}
+
+	var err error
+	switch {
+	case startDen >= 0:
+		m.start = m.dens[startDen].roleCell()
+	case userStart != nil:
 		m.start = *userStart
-	} else {
+	case m.entrance != nil:
+		from := []Point{generationStart}
+		if endDen >= 0 {
+			from = m.doors[endDen]
+		} else if exit != nil {
+			from = []Point{*exit}
+		} else if userEnd != nil {
+			from = []Point{*userEnd}
+		}
+		if m.start, err = m.placeOpening(*m.entrance, exit, from...); err != nil {
+			return fmt.Errorf("cannot place entrance: %w", err)
+		}
+	case endDen >= 0:
+		m.start, _, _ = m.findFarthestPoint(m.doors[endDen]...)
+	case exit != nil:
+		m.start, _, _ = m.findFarthestPoint(*exit)
+	default:
 		// If no start point was provided, find the longest path in the maze.
 		// The start of the longest path is the point farthest from the generation start.
-		m.start, _ = m.findFarthestPoint(generationStart)
+		m.start, _, _ = m.findFarthestPoint(generationStart)
 	}
 
-	if userEnd != nil {
+	from := []Point{m.start}
+	if startDen >= 0 {
+		from = m.doors[startDen]
+	}
+	switch {
+	case endDen >= 0:
+		m.end = m.dens[endDen].roleCell()
+	case userEnd != nil:
 		m.end = *userEnd
-	} else {
+	case exit != nil:
+		m.end = *exit
+	case m.exit != nil:
+		if m.end, err = m.placeOpening(*m.exit, &m.start, from...); err != nil {
+			return fmt.Errorf("cannot place exit: %w", err)
+		}
+	default:
 		// The end of the longest path is the point farthest from our new start point.
-		m.end, _ = m.findFarthestPoint(m.start)
+		m.end, _, _ = m.findFarthestPoint(from...)
 	}
 
-	// Place Start and End markers on the grid.
+	return m.markStartAndEnd()
+}
+
+// markStartAndEnd places the Start and End markers on the grid and records
+// the distance between them.
+func (m *Maze) markStartAndEnd() error {
 	m.grid[m.start.Y][m.start.X] = Start
 	m.grid[m.end.Y][m.end.X] = End
+	_, _, distances := m.findFarthestPoint(m.start)
+	m.placed.Distance = -1
+	if d, ok := distances[m.end]; ok {
+		m.placed.Distance = d
+	}
+	return nil
 }
 
-// connectDen finds all possible walls that can be turned into a door
-// between the maze and the den, and randomly picks one to open.
+// connectDen opens the doors of every den as its door plan says. The given
+// door side, or else the door point, applies to the first den if its plan
+// places no door explicitly.
 func (m *Maze) connectDen(r *rand.Rand, userDoor *Point, doorSide string) error {
-	if m.denWidth <= 0 || m.denHeight <= 0 {
-		return nil // No den to connect.
-	}
-
-	// Handle specified door side (e.g., "top", "bottom").
-	if doorSide != "" {
-		return m.connectDenAtSide(doorSide)
-	}
-
-	// If a specific door location is provided, validate and use it.
-	if userDoor != nil {
-		return m.connectDenAtPoint(*userDoor)
+	m.doors = make([][]Point, len(m.dens))
+	for i, d := range m.dens {
+		plan := d.DoorPlan
+		if i == 0 && len(plan.DoorSides) == 0 && len(plan.DoorPoints) == 0 {
+			if doorSide != "" {
+				plan.DoorSides = []string{doorSide}
+			} else if userDoor != nil {
+				plan.DoorPoints = []Point{*userDoor}
+			}
+		}
+		if err := m.openDoors(i, plan, r); err != nil {
+			return err
+		}
 	}
-
-	return m.connectRandomDenDoor(r)
+	return nil
 }
 
-// connectDenAtSide connects the den to the maze at the center of a specified wall.
-func (m *Maze) connectDenAtSide(doorSide string) error {
-	var door, neighbor Point
-	switch doorSide {
-	case "top":
-		door = Point{X: m.denStartX + m.denWidth/2, Y: m.denStartY - 1}
-		neighbor = Point{X: door.X, Y: door.Y - 1}
-	case "bottom":
-		door = Point{X: m.denStartX + m.denWidth/2, Y: m.denStartY + m.denHeight}
-		neighbor = Point{X: door.X, Y: door.Y + 1}
-	case "left":
-		door = Point{X: m.denStartX - 1, Y: m.denStartY + m.denHeight/2}
-		neighbor = Point{X: door.X - 1, Y: door.Y}
-	case "right":
-		door = Point{X: m.denStartX + m.denWidth, Y: m.denStartY + m.denHeight/2}
-		neighbor = Point{X: door.X + 1, Y: door.Y}
-	default:
-		return fmt.Errorf("invalid door side: %s. use 'top', 'bottom', 'left', or 'right'", doorSide)
+// connectDenAtSide connects a den to the maze at the center of a specified wall.
+func (m *Maze) connectDenAtSide(den int, doorSide string) error {
+	door, neighbor, ok, err := m.sideDoor(den, doorSide)
+	if err != nil {
+		return err
 	}
-
-	// Check if the door and neighbor are within bounds.
-	if door.X <= 0 || door.X >= m.width-1 || door.Y <= 0 || door.Y >= m.height-1 ||
-		neighbor.X <= 0 || neighbor.X >= m.width-1 || neighbor.Y <= 0 || neighbor.Y >= m.height-1 {
+	if !ok {
 		return fmt.Errorf("cannot place door on side '%s': too close to maze edge", doorSide)
 	}
 
 	// If the neighbor is already a path, we just need to open the door.
 	if m.grid[neighbor.Y][neighbor.X] == Path {
 		m.grid[door.Y][door.X] = Path
-		m.door = door
+		m.doors[den] = append(m.doors[den], door)
 		return nil
 	}
 
@@ -240,33 +465,79 @@ func (m *Maze) connectDenAtSide(doorSide string) error {
 
 	// Finally, open the door itself.
 	m.grid[door.Y][door.X] = Path
-	m.door = door
+	m.doors[den] = append(m.doors[den], door)
 	return nil
 }
 
-// connectDenAtPoint connects the den to the maze at a user-specified point.
-func (m *Maze) connectDenAtPoint(userDoor Point) error {
+// sideDoor finds the door of a den on the given side and the maze point just
+// outside it. The door faces outwards from the outermost den point in the middle
+// of the side. Where the den's shape makes that impossible, the nearest
+// position along the side that works is used instead.
+func (m *Maze) sideDoor(den int, side string) (door, neighbor Point, ok bool, err error) {
+	d := m.dens[den]
+	// at returns the point i steps along the side and k steps into the den's rectangle.
+	var at func(i, k int) Point
+	var out Point
+	length, depth := d.Width, d.Height
+	switch side {
+	case "top":
+		at = func(i, k int) Point { return Point{X: d.X + i, Y: d.Y + k} }
+		out = Point{X: 0, Y: -1}
+	case "bottom":
+		at = func(i, k int) Point { return Point{X: d.X + i, Y: d.Y + d.Height - 1 - k} }
+		out = Point{X: 0, Y: 1}
+	case "left":
+		at = func(i, k int) Point { return Point{X: d.X + k, Y: d.Y + i} }
+		out = Point{X: -1, Y: 0}
+		length, depth = d.Height, d.Width
+	case "right":
+		at = func(i, k int) Point { return Point{X: d.X + d.Width - 1 - k, Y: d.Y + i} }
+		out = Point{X: 1, Y: 0}
+		length, depth = d.Height, d.Width
+	default:
+		return Point{}, Point{}, false, fmt.Errorf("invalid door side: %s. use 'top', 'bottom', 'left', or 'right'", side)
+	}
+
+	inside := func(p Point) bool {
+		return p.X > 0 && p.X < m.width-1 && p.Y > 0 && p.Y < m.height-1 && !m.IsMasked(p)
+	}
+	// Try the middle first, then move outwards from it, alternating between both
+	// sides. Steps of two keep the door aligned with the maze cells like the middle.
+	for n := 0; n < 2*length; n++ {
+		i := length/2 + 2*((n+1)/2)
+		if n%2 == 1 {
+			i = length/2 - 2*((n+1)/2)
+		}
+		if i < 0 || i >= length {
+			continue
+		}
+		for k := 0; k < depth; k++ {
+			p := at(i, k)
+			if !d.contains(p) {
+				continue
+			}
+			door = Point{X: p.X + out.X, Y: p.Y + out.Y}
+			neighbor = Point{X: door.X + out.X, Y: door.Y + out.Y}
+			if inside(door) && inside(neighbor) && !m.IsInsideDen(neighbor) && !m.IsAdjacentToDen(neighbor) {
+				return door, neighbor, true, nil
+			}
+			break
+		}
+	}
+	return Point{}, Point{}, false, nil
+}
+
+// connectDenAtPoint connects a den to the maze at a user-specified point.
+func (m *Maze) connectDenAtPoint(den int, userDoor Point) error {
 	// 1. Must be a wall within the maze's inner boundaries.
 	if userDoor.X <= 0 || userDoor.X >= m.width-1 || userDoor.Y <= 0 || userDoor.Y >= m.height-1 || m.grid[userDoor.Y][userDoor.X] != Wall {
 		return fmt.Errorf("invalid door location at %+v: not a valid wall position", userDoor)
 	}
 
 	// 2. Must be on the boundary of the den, connecting an inner path to an outer path.
-	// Check for a horizontal connection: Path-Wall-Path
-	p1_h := Point{X: userDoor.X - 1, Y: userDoor.Y}
-	p2_h := Point{X: userDoor.X + 1, Y: userDoor.Y}
-	if m.grid[p1_h.Y][p1_h.X] == Path && m.grid[p2_h.Y][p2_h.X] == Path && m.IsInsideDen(p1_h) != m.IsInsideDen(p2_h) {
+	if m.isDoorCandidate(den, userDoor) {
 		m.grid[userDoor.Y][userDoor.X] = Path
-		m.door = userDoor
-		return nil
-	}
-
-	// Check for a vertical connection: Path-Wall-Path
-	p1_v := Point{X: userDoor.X, Y: userDoor.Y - 1}
-	p2_v := Point{X: userDoor.X, Y: userDoor.Y + 1}
-	if m.grid[p1_v.Y][p1_v.X] == Path && m.grid[p2_v.Y][p2_v.X] == Path && m.IsInsideDen(p1_v) != m.IsInsideDen(p2_v) {
-		m.grid[userDoor.Y][userDoor.X] = Path
-		m.door = userDoor
+		m.doors[den] = append(m.doors[den], userDoor)
 		return nil
 	}
 
@@ -274,57 +545,97 @@ func (m *Maze) connectDenAtPoint(userDoor Point) error {
 }
 
 // connectRandomDenDoor finds all possible walls that can be turned into a door
-// and randomly picks one to open.
-func (m *Maze) connectRandomDenDoor(r *rand.Rand) error {
-	var potentialDoors []Point
+// of a den and randomly picks one to open, n times. Every door keeps at least
+// gap cells away from the den's other doors along its wall.
+func (m *Maze) connectRandomDenDoor(den, n, gap int, r *rand.Rand) error {
+	for i := 0; i < n; i++ {
+		potentialDoors := m.doorCandidates(den, gap)
+		if len(potentialDoors) == 0 {
+			if len(m.doors[den]) == 0 {
+				return nil // It's not an error if no potential doors are found.
+			}
+			return fmt.Errorf("cannot place %d doors at least %d cells apart", len(m.doors[den])+n-i, gap)
+		}
+
+		// Pick a random door from all possibilities and open it.
+		door := potentialDoors[r.Intn(len(potentialDoors))]
+		m.grid[door.Y][door.X] = Path
+		m.doors[den] = append(m.doors[den], door)
+	}
+	return nil
+}
+
+// doorCandidates returns, in row-major order, all walls that can be turned into
+// a door of a den at least gap cells away from its open doors.
+func (m *Maze) doorCandidates(den, gap int) []Point {
+	var distances []map[Point]int
+	if gap > 1 {
+		for _, door := range m.doors[den] {
+			distances = append(distances, m.wallDistances(den, door))
+		}
+	}
 
+	var potentialDoors []Point
 	// Iterate through the grid to find walls that separate the den from the maze path.
 	for y := 1; y < m.height-1; y++ {
 		for x := 1; x < m.width-1; x++ {
-			// We are looking for a Wall cell to serve as a door.
-			if m.grid[y][x] != Wall {
-				continue
+			p := Point{X: x, Y: y}
+			if m.isDoorCandidate(den, p) && farEnough(distances, p, gap) {
+				potentialDoors = append(potentialDoors, p)
 			}
+		}
+	}
+	return potentialDoors
+}
 
-			// Check for a horizontal separation: Path-Wall-Path
-			p1_h := Point{X: x - 1, Y: y}
-			p2_h := Point{X: x + 1, Y: y}
-			if m.grid[p1_h.Y][p1_h.X] == Path && m.grid[p2_h.Y][p2_h.X] == Path {
-				// If one side is in the den and the other isn't, it's a valid door.
-				if m.IsInsideDen(p1_h) != m.IsInsideDen(p2_h) {
-					potentialDoors = append(potentialDoors, Point{X: x, Y: y})
-					continue // Found a candidate, move to the next wall cell.
-				}
-			}
+// isDoorCandidate reports whether a wall point can serve as a door of a den,
+// that is whether it separates a path inside the den from a maze path outside it.
+func (m *Maze) isDoorCandidate(den int, p Point) bool {
+	// We are looking for a Wall cell to serve as a door.
+	if m.grid[p.Y][p.X] != Wall {
+		return false
+	}
 
-			// Check for a vertical separation: Path-Wall-Path
-			p1_v := Point{X: x, Y: y - 1}
-			p2_v := Point{X: x, Y: y + 1}
-			if m.grid[p1_v.Y][p1_v.X] == Path && m.grid[p2_v.Y][p2_v.X] == Path {
-				if m.IsInsideDen(p1_v) != m.IsInsideDen(p2_v) {
-					potentialDoors = append(potentialDoors, Point{X: x, Y: y})
-				}
-			}
+	// Check for a horizontal separation: Path-Wall-Path
+	p1_h := Point{X: p.X - 1, Y: p.Y}
+	p2_h := Point{X: p.X + 1, Y: p.Y}
+	if m.grid[p1_h.Y][p1_h.X] == Path && m.grid[p2_h.Y][p2_h.X] == Path {
+		// If one side is in the den and the other isn't, it's a valid door.
+		if (m.denAt(p1_h) == den) != (m.denAt(p2_h) == den) {
+			return true
 		}
 	}
 
-	if len(potentialDoors) > 0 {
-		// Pick a random door from all possibilities and open it.
-		door := potentialDoors[r.Intn(len(potentialDoors))]
-		m.grid[door.Y][door.X] = Path
-		m.door = door
+	// Check for a vertical separation: Path-Wall-Path
+	p1_v := Point{X: p.X, Y: p.Y - 1}
+	p2_v := Point{X: p.X, Y: p.Y + 1}
+	if m.grid[p1_v.Y][p1_v.X] == Path && m.grid[p2_v.Y][p2_v.X] == Path {
+		if (m.denAt(p1_v) == den) != (m.denAt(p2_v) == den) {
+			return true
+		}
 	}
-
-	return nil // It's not an error if no potential doors are found.
+	return false
 }
 
 // carvePathToNearest finds the closest maze path from a starting point (through walls)
 // and carves a corridor to connect them.
 func (m *Maze) carvePathToNearest(start Point) error {
-	// This function uses BFS to find the nearest Path cell, exploring only through Wall cells.
-	queue := []Point{start}
+	if !m.carvePathFrom([]Point{start}, func(p Point) bool { return m.grid[p.Y][p.X] == Path }) {
+		return fmt.Errorf("no path found to connect the door to the maze")
+	}
+	return nil
+}
+
+// carvePathFrom finds the closest target from any of the starting points,
+// exploring only through walls, and carves a corridor to connect them.
+// It reports whether a target was reached.
+func (m *Maze) carvePathFrom(starts []Point, isTarget func(p Point) bool) bool {
+	// This function uses BFS to find the nearest target, exploring only through Wall cells.
+	queue := append([]Point(nil), starts...)
 	visited := make(map[Point]bool)
-	visited[start] = true
+	for _, start := range starts {
+		visited[start] = true
+	}
 	parent := make(map[Point]Point)
 
 	var targetPath Point
@@ -338,13 +649,14 @@ func (m *Maze) carvePathToNearest(start Point) error {
 		for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
 			next := Point{X: current.X + dir.X, Y: current.Y + dir.Y}
 
-			// Check bounds
-			if next.X <= 0 || next.X >= m.width-1 || next.Y <= 0 || next.Y >= m.height-1 {
+			// Check bounds, including the mask, and keep out of the dens and their walls.
+			if next.X <= 0 || next.X >= m.width-1 || next.Y <= 0 || next.Y >= m.height-1 || m.IsMasked(next) ||
+				m.IsInsideDen(next) || m.IsAdjacentToDen(next) || visited[next] {
 				continue
 			}
 
-			// If we found an existing maze path, we're done searching.
-			if m.grid[next.Y][next.X] == Path {
+			// If we found a target, we're done searching.
+			if isTarget(next) {
 				parent[next] = current
 				targetPath = next
 				pathFound = true
@@ -353,39 +665,41 @@ func (m *Maze) carvePathToNearest(start Point) error {
 
 			// Otherwise, if it's a wall we haven't visited, add it to the queue.
 			if m.grid[next.Y][next.X] == Wall {
-				if _, ok := visited[next]; !ok {
-					visited[next] = true
-					parent[next] = current
-					queue = append(queue, next)
-				}
+				visited[next] = true
+				parent[next] = current
+				queue = append(queue, next)
 			}
 		}
 	}
 
 	if !pathFound {
-		return fmt.Errorf("no path found to connect the door to the maze")
+		return false
 	}
 
-	// Backtrack from the target path to the start point, carving a path.
+	// Backtrack from the target to the start point, carving a path.
 	p := targetPath
-	for p != start {
+	for {
 		p = parent[p]
 		m.grid[p.Y][p.X] = Path
+		if _, ok := parent[p]; !ok {
+			return true // Only the starting points have no parent.
+		}
 	}
-
-	return nil
 }
 
-// findFarthestPoint performs a BFS from a given start point to find the
-// cell that is the farthest away along the maze paths.
-// It returns the farthest point and its distance.
-func (m *Maze) findFarthestPoint(start Point) (farthestPoint Point, maxDistance int) {
-	queue := []Point{start}
+// findFarthestPoint performs a BFS from the given start points to find the
+// cell that is the farthest away from all of them along the maze paths.
+// It returns the farthest point, its distance, and the distances of all
+// points the search reached, which include any openings in the outer wall.
+func (m *Maze) findFarthestPoint(starts ...Point) (farthestPoint Point, maxDistance int, distances map[Point]int) {
+	queue := append([]Point(nil), starts...)
 	// distances map also serves as the visited set
-	distances := make(map[Point]int)
-	distances[start] = 0
+	distances = make(map[Point]int)
+	for _, start := range starts {
+		distances[start] = 0
+	}
 
-	farthestPoint = start
+	farthestPoint = starts[0]
 	maxDistance = 0
 
 	head := 0
@@ -395,18 +709,19 @@ func (m *Maze) findFarthestPoint(start Point) (farthestPoint Point, maxDistance
 
 		// Explore neighbors
 		for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
-			next := Point{X: current.X + dir.X, Y: current.Y + dir.Y}
+			next := m.step(current, dir)
 
 			// Check if the neighbor is a valid path and hasn't been visited.
-			if next.X > 0 && next.X < m.width-1 && next.Y > 0 && next.Y < m.height-1 && m.grid[next.Y][next.X] != Wall {
+			if m.onGrid(next) && m.grid[next.Y][next.X] != Wall && !m.IsMasked(next) {
 				if _, visited := distances[next]; !visited {
 					dist := distances[current] + 1
 					distances[next] = dist
 					queue = append(queue, next)
 
 					// Update the farthest point only if it's not inside the den.
-					// Also ensure it's not on the den's wall (i.e., the door).
-					if dist > maxDistance && !m.IsInsideDen(next) && !m.IsAdjacentToDen(next) {
+					// Also ensure it's not on the den's wall (i.e., the door)
+					// or an opening in the outer wall.
+					if dist > maxDistance && m.inside(next) && !m.IsInsideDen(next) && !m.IsAdjacentToDen(next) {
 						maxDistance = dist
 						farthestPoint = next
 					}
@@ -414,5 +729,10 @@ func (m *Maze) findFarthestPoint(start Point) (farthestPoint Point, maxDistance
 			}
 		}
 	}
-	return farthestPoint, maxDistance
+	return farthestPoint, maxDistance, distances
+}
+
+// onGrid reports whether a point lies within the grid, outer wall included.
+func (m *Maze) onGrid(p Point) bool {
+	return p.X >= 0 && p.X < m.width && p.Y >= 0 && p.Y < m.height
 }
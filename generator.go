@@ -0,0 +1,191 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Generator carves the paths of a maze.
+// Carve is called on a grid where every cell outside the den is a Wall,
+// with the random source to draw from and the point generation should grow from.
+// Den doors and the Start and End markers are added by Generate afterwards.
+type Generator interface {
+	Carve(m *Maze, r *rand.Rand, start Point) error
+}
+
+// DFS is the default generator. It carves the maze with an iterative
+// randomized depth-first search (a recursive backtracker), which produces
+// long, winding corridors with few branches. The maze bias, or the bias map
+// at the current cell, controls how often a corridor keeps its direction.
+type DFS struct{}
+
+// Carve implements Generator.
+func (d DFS) Carve(m *Maze, r *rand.Rand, start Point) error {
+	return m.carveCells(d, r, start)
+}
+
+// wrapSafe implements wrapCarver.
+func (DFS) wrapSafe() {}
+
+// carveGraph implements graphCarver.
+func (DFS) carveGraph(g cellGraph, r *rand.Rand, start int) error {
+	visited := make([]bool, g.size())
+	visited[start] = true
+	stack := []int{start}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		neighbors := unvisited(g, current, visited)
+		if len(neighbors) == 0 {
+			// No unvisited neighbors: backtrack.
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		previous := -1
+		if len(stack) > 1 {
+			previous = stack[len(stack)-2]
+		}
+		next := chooseNeighbor(g, r, previous, current, neighbors)
+		g.link(current, next)
+		visited[next] = true
+		stack = append(stack, next)
+	}
+	return nil
+}
+
+// generators maps the names accepted by ParseGenerator to constructors
+// that build the generator from its options.
+var generators = map[string]func(opts options) (Generator, error){
+	"aldousbroder": plain(AldousBroder{}),
+	"binarytree":   newBinaryTree,
+	"cave":         newCave,
+	"dfs":          plain(DFS{}),
+	"division":     newDivision,
+	"eller":        plain(Eller{}),
+	"growingtree":  newGrowingTree,
+	"huntandkill":  plain(HuntAndKill{}),
+	"kruskal":      plain(Kruskal{}),
+	"prim":         plain(Prim{}),
+	"sidewinder":   plain(Sidewinder{}),
+	"wilson":       plain(Wilson{}),
+}
+
+// plain returns a constructor for a generator that takes no options.
+func plain(g Generator) func(opts options) (Generator, error) {
+	return func(opts options) (Generator, error) {
+		return g, opts.check()
+	}
+}
+
+// ParseGenerator returns the generator described by spec, which is a
+// generator name optionally followed by a colon and comma-separated options,
+// for example "dfs" or "division:min=3,pref=0.5". Names are case-insensitive.
+func ParseGenerator(spec string) (Generator, error) {
+	name, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	newGenerator, ok := generators[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown generation algorithm: %q. use one of: %s", name, strings.Join(GeneratorNames(), ", "))
+	}
+	opts, err := parseOptions(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options for %s: %w", name, err)
+	}
+	g, err := newGenerator(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options for %s: %w", name, err)
+	}
+	return g, nil
+}
+
+// generatorName returns the name that ParseGenerator knows a generator by, or
+// its type if it is not a built-in one.
+func generatorName(g Generator) string {
+	for _, name := range GeneratorNames() {
+		if built, err := generators[name](options{}); err == nil && reflect.TypeOf(built) == reflect.TypeOf(g) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", g)
+}
+
+// GeneratorNames returns the sorted names of all built-in generators. Of
+// these, aldousbroder, dfs, growingtree, huntandkill, kruskal, prim and
+// wilson grow the maze from cell to cell; the others sweep its rows and
+// columns, divide it or grow a cave.
+func GeneratorNames() []string {
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// options holds the key=value options of a generator spec.
+// Constructors delete the keys they use, so that check can report the rest.
+type options map[string]string
+
+// parseOptions parses a comma-separated list of key=value pairs.
+// A key given without a value gets an empty value.
+func parseOptions(s string) (options, error) {
+	opts := make(options)
+	if strings.TrimSpace(s) == "" {
+		return opts, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, _ := strings.Cut(pair, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			return nil, fmt.Errorf("option %q has no name", pair)
+		}
+		if _, dup := opts[key]; dup {
+			return nil, fmt.Errorf("option %q is given twice", key)
+		}
+		opts[key] = strings.TrimSpace(value)
+	}
+	return opts, nil
+}
+
+// float removes the named option and returns its value, or def if it is not set.
+func (o options) float(key string, def float64) (float64, error) {
+	value, ok := o[key]
+	if !ok {
+		return def, nil
+	}
+	delete(o, key)
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("option %s: %q is not a number", key, value)
+	}
+	return f, nil
+}
+
+// int removes the named option and returns its value, or def if it is not set.
+func (o options) int(key string, def int) (int, error) {
+	value, ok := o[key]
+	if !ok {
+		return def, nil
+	}
+	delete(o, key)
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("option %s: %q is not an integer", key, value)
+	}
+	return i, nil
+}
+
+// check reports any options that were not used.
+func (o options) check() error {
+	if len(o) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("unknown option(s): %s", strings.Join(keys, ", "))
+}
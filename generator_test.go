@@ -0,0 +1,160 @@
+package maze_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// straightLine is a minimal custom generator that carves a single corridor
+// along the first row of cells.
+type straightLine struct{}
+
+func (straightLine) Carve(m *maze.Maze, r *rand.Rand, start maze.Point) error {
+	for x := 1; x < m.Width()-1; x++ {
+		m.SetCell(x, 1, maze.Path)
+	}
+	return nil
+}
+
+func TestParseGenerator(t *testing.T) {
+	g, err := maze.ParseGenerator("DFS")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if _, ok := g.(maze.DFS); !ok {
+		t.Errorf("Expected a DFS generator, got %T", g)
+	}
+
+	if _, err := maze.ParseGenerator("nope"); err == nil {
+		t.Error("Expected error for unknown algorithm, but got nil")
+	}
+}
+
+func TestSetGenerator(t *testing.T) {
+	m, err := maze.New(11, 5, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if _, ok := m.Generator().(maze.DFS); !ok {
+		t.Errorf("Expected DFS as the default generator, got %T", m.Generator())
+	}
+
+	if err := m.SetGenerator(straightLine{}); err != nil {
+
+		t.Fatalf("Failed to set generator: %v", err)
+
+	}
+	start := maze.Point{X: 1, Y: 1}
+	if err := m.Generate(1, &start, nil, nil, "", 0); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.End() != (maze.Point{X: 9, Y: 1}) {
+		t.Errorf("Expected end at the far end of the corridor, got %+v", m.End())
+	}
+	for x := 0; x < m.Width(); x++ {
+		if cell, _ := m.Cell(x, 3); cell != maze.Wall {
+			t.Errorf("Expected untouched row to stay a wall at x=%d, got '%c'", x, cell)
+		}
+	}
+
+	// Generating twice must start from a fresh grid.
+	if err := m.SetGenerator(nil); err != nil {
+		t.Fatalf("Failed to set generator: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if _, found := m.Solve(); !found {
+		t.Error("Regenerated maze should be solvable, but it is not")
+	}
+}
+
+// gridString renders the maze cells row by row.
+func gridString(m *maze.Maze) string {
+	var b []rune
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			cell, _ := m.Cell(x, y)
+			b = append(b, rune(cell))
+		}
+		b = append(b, '\n')
+	}
+	return string(b)
+}
+
+// checkPerfectMaze verifies that every cell outside the dens is carved, that the
+// carved cells form a spanning tree, and that each den is only entered by its doors.
+func checkPerfectMaze(t *testing.T, m *maze.Maze) {
+	t.Helper()
+	open := func(x, y int) bool {
+		cell, ok := m.Cell(x, y)
+		return ok && cell != maze.Wall
+	}
+
+	cells, passages := 0, 0
+	for y := 1; y < m.Height()-1; y += 2 {
+		for x := 1; x < m.Width()-1; x += 2 {
+			if m.IsInsideDen(maze.Point{X: x, Y: y}) {
+				continue
+			}
+			if !open(x, y) {
+				t.Fatalf("Expected cell %d,%d to be carved", x, y)
+			}
+			cells++
+			if x+2 < m.Width()-1 && open(x+1, y) && !m.IsInsideDen(maze.Point{X: x + 2, Y: y}) {
+				passages++
+			}
+			if y+2 < m.Height()-1 && open(x, y+1) && !m.IsInsideDen(maze.Point{X: x, Y: y + 2}) {
+				passages++
+			}
+		}
+	}
+	if passages != cells-1 {
+		t.Errorf("Expected a spanning tree with %d passages, got %d", cells-1, passages)
+	}
+
+	doors := 0
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			if open(x, y) && m.IsAdjacentToDen(maze.Point{X: x, Y: y}) {
+				doors++
+			}
+		}
+	}
+	if doors != len(m.Doors()) {
+		t.Errorf("Expected the den walls to have exactly %d doors, got %d openings", len(m.Doors()), doors)
+	}
+	for i := range m.Dens() {
+		if len(m.DenDoors(i)) == 0 {
+			t.Errorf("Expected den %d to have a door", i)
+		}
+	}
+
+	if _, found := m.Solve(); !found {
+		t.Error("Generated maze should be solvable, but it is not")
+	}
+}
+
+// checkReproducible verifies that the same seed yields the same maze.
+func checkReproducible(t *testing.T, g maze.Generator) {
+	t.Helper()
+	var grids [2]string
+	for i := range grids {
+		m, err := maze.New(31, 21, 7, 5)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(g); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(7, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		grids[i] = gridString(m)
+	}
+	if grids[0] != grids[1] {
+		t.Errorf("Expected identical mazes for the same seed, got\n%s\nand\n%s", grids[0], grids[1])
+	}
+}
@@ -0,0 +1,461 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+)
+
+// graph is a maze whose cells are given by which cells border which, rather
+// than by a square grid. The mazes on other grids, such as HexMaze, keep
+// their cells and passages in one and share its generation and solving.
+type graph struct {
+	// adj lists the neighbors of each cell, one per side, with -1 for a side
+	// on the border of the maze. Rendering relies on the order of the sides.
+	adj [][]int
+	// linked records, parallel to adj, which sides have a passage.
+	linked [][]bool
+	// ahead returns the neighbor that continues straight on from prev
+	// through cur, or -1. It may be nil if the grid has no straight lines.
+	ahead func(prev, cur int) int
+	// kind names the grid in errors, such as "hexagonal".
+	kind string
+	// bias is the chance of a corridor going straight on, set by generate.
+	bias float64
+
+	den   []bool
+	door  [2]int // The den cell and the maze cell the door joins, or -1s.
+	start int
+	end   int
+}
+
+// cellGraph is a maze as the generators that work on any grid see it: cells
+// numbered from 0, the cells each one may be joined to, and a way to join
+// them. Both graph and the square grid of a Maze provide one, so that each of
+// these generators is written once for all grids.
+type cellGraph interface {
+	// size returns the number of cell numbers, some of which may be unused.
+	size() int
+	// cells returns the cells a generator carves, in scanning order.
+	cells() []int
+	// neighbors returns the cells that cell c may be joined to.
+	neighbors(c int) []int
+	// link opens the passage between two neighboring cells.
+	link(a, b int)
+	// straight returns the neighbor that continues straight on from prev
+	// through cur and may be joined to cur, or -1.
+	straight(prev, cur int) int
+	// biasAt returns the chance of a corridor going straight on at cell c.
+	biasAt(c int) float64
+	// pick chooses one of the neighbors of cur for a move that arrived from
+	// prev, or from nowhere if prev is -1, honouring any direction weights.
+	pick(r *rand.Rand, prev, cur int, neighbors []int) int
+	// weighted reports whether the grid has direction weights that favor
+	// some passages. Without them, generators draw from the random source
+	// as they did before weights existed, so that seeds keep their mazes.
+	weighted() bool
+	// weight returns the direction weight of a passage from one cell to another.
+	weight(from, to int) float64
+}
+
+// graphCarver is implemented by the generators that can carve a maze on any
+// cellGraph, not only on the square grid.
+type graphCarver interface {
+	carveGraph(g cellGraph, r *rand.Rand, start int) error
+}
+
+// newGraph creates a graph of n cells without neighbors.
+func newGraph(kind string, n int) *graph {
+	return &graph{
+		kind:   kind,
+		adj:    make([][]int, n),
+		linked: make([][]bool, n),
+		den:    make([]bool, n),
+		door:   [2]int{-1, -1},
+		start:  -1,
+		end:    -1,
+	}
+}
+
+// setNeighbors sets the neighbors of cell c, one per side.
+func (g *graph) setNeighbors(c int, neighbors ...int) {
+	g.adj[c] = neighbors
+	g.linked[c] = make([]bool, len(neighbors))
+}
+
+// side returns the side of cell a that borders cell b, or -1.
+func (g *graph) side(a, b int) int {
+	for k, n := range g.adj[a] {
+		if n == b {
+			return k
+		}
+	}
+	return -1
+}
+
+// setLink opens or closes the passage between two neighboring cells.
+func (g *graph) setLink(a, b int, open bool) {
+	for _, e := range [][2]int{{a, b}, {b, a}} {
+		// A cell may border another on several sides, as in a polar grid's
+		// innermost ring, so every side between them is set.
+		for k, n := range g.adj[e[0]] {
+			if n == e[1] {
+				g.linked[e[0]][k] = open
+			}
+		}
+	}
+}
+
+// link opens the passage between two neighboring cells. It implements cellGraph.
+func (g *graph) link(a, b int) {
+	g.setLink(a, b, true)
+}
+
+// straight implements cellGraph.
+func (g *graph) straight(prev, cur int) int {
+	if g.ahead == nil {
+		return -1
+	}
+	if n := g.ahead(prev, cur); n >= 0 && !g.den[n] {
+		return n
+	}
+	return -1
+}
+
+// biasAt implements cellGraph. The bias is the same everywhere.
+func (g *graph) biasAt(c int) float64 {
+	return g.bias
+}
+
+// pick implements cellGraph. The grids other than the square one have no
+// direction weights, so every neighbor is equally likely.
+func (g *graph) pick(r *rand.Rand, prev, cur int, neighbors []int) int {
+	return neighbors[r.Intn(len(neighbors))]
+}
+
+// weighted implements cellGraph.
+func (g *graph) weighted() bool {
+	return false
+}
+
+// weight implements cellGraph.
+func (g *graph) weight(from, to int) float64 {
+	return 1
+}
+
+// isLinked reports whether there is a passage between two cells.
+func (g *graph) isLinked(a, b int) bool {
+	k := g.side(a, b)
+	return k >= 0 && g.linked[a][k]
+}
+
+// neighbors returns the neighbors of cell c that a generator may carve to:
+// those outside the den. It implements cellGraph.
+func (g *graph) neighbors(c int) []int {
+	var out []int
+	for _, n := range g.adj[c] {
+		if n >= 0 && !g.den[n] && !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// size implements cellGraph.
+func (g *graph) size() int {
+	return len(g.adj)
+}
+
+// cells returns the cells outside the den in index order. It implements cellGraph.
+func (g *graph) cells() []int {
+	var out []int
+	for c := range g.adj {
+		if !g.den[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// reset closes every passage except those inside the den.
+func (g *graph) reset() {
+	for c := range g.adj {
+		for k, n := range g.adj[c] {
+			g.linked[c][k] = n >= 0 && g.den[c] && g.den[n]
+		}
+	}
+	g.door = [2]int{-1, -1}
+	g.start, g.end = -1, -1
+}
+
+// generate carves the maze with the given generator and places its door,
+// Start and End. The Start and End may be -1 to place them automatically
+// at the two ends of the longest path. Callers decide whether they may be
+// inside the den.
+func (g *graph) generate(seed int64, gen Generator, bias float64, start, end int) error {
+	carver, ok := gen.(graphCarver)
+	if !ok {
+		return fmt.Errorf("generator %T does not support %s grids", gen, g.kind)
+	}
+	if start >= 0 && start == end {
+		return fmt.Errorf("start and end points cannot be the same")
+	}
+
+	// Generation grows from the given start or end, or from a random cell,
+	// but never from inside the den, which is carved already.
+	r := rand.New(rand.NewSource(seed))
+	g.reset()
+	g.bias = bias
+	generationStart := -1
+	for _, c := range []int{start, end} {
+		if generationStart < 0 && c >= 0 && !g.den[c] {
+			generationStart = c
+		}
+	}
+	if generationStart < 0 {
+		cells := g.cells()
+		if len(cells) == 0 {
+			return fmt.Errorf("could not find a valid random starting point for generation")
+		}
+		generationStart = cells[r.Intn(len(cells))]
+	}
+	if err := carver.carveGraph(g, r, generationStart); err != nil {
+		return err
+	}
+	g.openDoor(r)
+
+	switch {
+	case start >= 0 && end >= 0:
+	case start >= 0:
+		end, _ = g.farthest(start)
+	case end >= 0:
+		start, _ = g.farthest(end)
+	default:
+		start, _ = g.farthest(generationStart)
+		end, _ = g.farthest(start)
+	}
+	g.start, g.end = start, end
+	return nil
+}
+
+// openDoor links a random cell of the den to a neighbor outside it.
+func (g *graph) openDoor(r *rand.Rand) {
+	var candidates [][2]int
+	for c := range g.adj {
+		if !g.den[c] {
+			continue
+		}
+		for _, n := range g.adj[c] {
+			if n >= 0 && !g.den[n] {
+				candidates = append(candidates, [2]int{c, n})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	g.door = candidates[r.Intn(len(candidates))]
+	g.link(g.door[0], g.door[1])
+}
+
+// distances returns the number of steps along the passages from the given
+// cells to every cell, or -1 for cells that cannot be reached.
+func (g *graph) distances(from ...int) []int {
+	dist := make([]int, len(g.adj))
+	for i := range dist {
+		dist[i] = -1
+	}
+	queue := append([]int(nil), from...)
+	for _, c := range from {
+		dist[c] = 0
+	}
+	for head := 0; head < len(queue); head++ {
+		c := queue[head]
+		for k, n := range g.adj[c] {
+			if n >= 0 && g.linked[c][k] && dist[n] < 0 {
+				dist[n] = dist[c] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
+
+// farthest returns the cell outside the den that is farthest along the
+// passages from the given cells, and its distance.
+func (g *graph) farthest(from ...int) (int, int) {
+	best, bestDistance := from[0], 0
+	for c, d := range g.distances(from...) {
+		if d > bestDistance && !g.den[c] {
+			best, bestDistance = c, d
+		}
+	}
+	return best, bestDistance
+}
+
+// solve returns the shortest route from the Start to the End.
+func (g *graph) solve() ([]int, bool) {
+	if g.start < 0 || g.end < 0 {
+		return nil, false
+	}
+	dist := g.distances(g.end)
+	if dist[g.start] < 0 {
+		return nil, false
+	}
+	// Walk downhill from the Start, which yields the route in order.
+	path := []int{g.start}
+	for c := g.start; c != g.end; {
+		for k, n := range g.adj[c] {
+			if n >= 0 && g.linked[c][k] && dist[n] == dist[c]-1 {
+				c = n
+				break
+			}
+		}
+		path = append(path, c)
+	}
+	return path, true
+}
+
+// unvisited returns the neighbors of cell c that are not yet visited.
+func unvisited(g cellGraph, c int, visited []bool) []int {
+	var out []int
+	for _, n := range g.neighbors(c) {
+		if !visited[n] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// chooseNeighbor picks one of the unvisited neighbors of cur, keeping
+// straight on from prev with the chance of the bias at cur where the grid
+// allows it, and otherwise as the grid's pick does.
+func chooseNeighbor(g cellGraph, r *rand.Rand, prev, cur int, neighbors []int) int {
+	if prev >= 0 {
+		if straight := g.straight(prev, cur); straight >= 0 && slices.Contains(neighbors, straight) && r.Float64() < g.biasAt(cur) {
+			return straight
+		}
+	}
+	return g.pick(r, prev, cur, neighbors)
+}
+
+// connectedCells returns the cells that can be joined to start by carving,
+// in breadth-first order, starting with start itself. Random walks must be
+// confined to these cells, or they could never reach the maze.
+func connectedCells(g cellGraph, start int) []int {
+	seen := make([]bool, g.size())
+	seen[start] = true
+	cells := []int{start}
+	for head := 0; head < len(cells); head++ {
+		for _, n := range g.neighbors(cells[head]) {
+			if !seen[n] {
+				seen[n] = true
+				cells = append(cells, n)
+			}
+		}
+	}
+	return cells
+}
+
+// graphMaze is what the mazes on other grids than the square one have in
+// common: a graph of their cells, a generator, and the conversion between
+// the Points that address their cells and the cells of the graph.
+type graphMaze struct {
+	*graph
+	generator Generator
+	// cellAt returns the graph cell at p, or -1 if p is not a cell.
+	cellAt func(p Point) int
+	// pointOf returns the Point of a graph cell.
+	pointOf func(c int) Point
+}
+
+// SetGenerator sets the algorithm used by Generate. A nil generator restores
+// the default, DFS. Only the generators that grow from cell to cell (see
+// GeneratorNames) can carve grids other than the square one.
+func (m *graphMaze) SetGenerator(g Generator) error {
+	if _, ok := g.(graphCarver); g != nil && !ok {
+		return fmt.Errorf("generator %T does not support %s grids", g, m.kind)
+	}
+	m.generator = g
+	return nil
+}
+
+// Generator returns the algorithm used by Generate.
+func (m *graphMaze) Generator() Generator {
+	if m.generator == nil {
+		return DFS{}
+	}
+	return m.generator
+}
+
+// cell validates a start or end point given to Generate and returns its
+// graph cell, or -1 if it is nil.
+func (m *graphMaze) cell(p *Point, pointType string, inDen bool) (int, error) {
+	if p == nil {
+		return -1, nil
+	}
+	c := m.cellAt(*p)
+	if c < 0 {
+		return -1, fmt.Errorf("invalid %s point: %+v. must be within the grid", pointType, *p)
+	}
+	if m.den[c] && !inDen {
+		return -1, fmt.Errorf("invalid %s point: %+v. cannot be inside the den", pointType, *p)
+	}
+	return c, nil
+}
+
+// Start returns the cell of the maze's Start.
+func (m *graphMaze) Start() Point { return m.pointOf(m.start) }
+
+// End returns the cell of the maze's End.
+func (m *graphMaze) End() Point { return m.pointOf(m.end) }
+
+// IsInsideDen reports whether cell p is part of the den.
+func (m *graphMaze) IsInsideDen(p Point) bool {
+	c := m.cellAt(p)
+	return c >= 0 && m.den[c]
+}
+
+// Door returns the den cell and the cell outside the den that the den's door
+// joins, and false if the maze has no den or has not been generated.
+func (m *graphMaze) Door() (Point, Point, bool) {
+	if m.door[0] < 0 {
+		return Point{}, Point{}, false
+	}
+	return m.pointOf(m.door[0]), m.pointOf(m.door[1]), true
+}
+
+// Neighbors returns the cells next to cell p, whether or not a wall is in between.
+func (m *graphMaze) Neighbors(p Point) []Point {
+	c := m.cellAt(p)
+	if c < 0 {
+		return nil
+	}
+	var out []Point
+	for _, n := range m.adj[c] {
+		if n >= 0 && !slices.Contains(out, m.pointOf(n)) {
+			out = append(out, m.pointOf(n))
+		}
+	}
+	return out
+}
+
+// Linked reports whether there is a passage between cells a and b.
+func (m *graphMaze) Linked(a, b Point) bool {
+	ca, cb := m.cellAt(a), m.cellAt(b)
+	return ca >= 0 && cb >= 0 && m.isLinked(ca, cb)
+}
+
+// Solve finds the shortest path from Start to End and returns its cells.
+// It returns the path and true if a path is found, otherwise nil and false.
+func (m *graphMaze) Solve() ([]Point, bool) {
+	path, ok := m.solve()
+	if !ok {
+		return nil, false
+	}
+	points := make([]Point, len(path))
+	for i, c := range path {
+		points[i] = m.pointOf(c)
+	}
+	return points, true
+}
@@ -0,0 +1,106 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// GrowingTree carves the maze with the growing tree algorithm.
+// It keeps a list of active cells, starting with the start point. At each step
+// it picks an active cell, carves a passage to one of its unvisited neighbors
+// and makes that neighbor active, or retires the cell if it has none left.
+// How the active cell is picked sets the texture of the maze: always taking the
+// newest is a depth-first search with long corridors, always taking the oldest
+// gives long straight spokes, and picking at random behaves like Prim's algorithm.
+//
+// The weights give the relative chance of each policy at every step; for example
+// Newest 0.75 and Random 0.25 mixes mostly long corridors with some branching.
+// If all weights are zero, the newest cell is always taken. The maze bias is
+// applied as in DFS when choosing the neighbor to carve to.
+type GrowingTree struct {
+	Newest float64
+	Oldest float64
+	Random float64
+}
+
+// newGrowingTree builds a GrowingTree from the "newest", "oldest" and "random"
+// options. A policy given without a weight gets weight 1.
+func newGrowingTree(opts options) (Generator, error) {
+	var g GrowingTree
+	for _, policy := range []struct {
+		key    string
+		weight *float64
+	}{{"newest", &g.Newest}, {"oldest", &g.Oldest}, {"random", &g.Random}} {
+		if value, ok := opts[policy.key]; ok && value == "" {
+			opts[policy.key] = "1"
+		}
+		w, err := opts.float(policy.key, 0)
+		if err != nil {
+			return nil, err
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("option %s must not be negative", policy.key)
+		}
+		*policy.weight = w
+	}
+	return g, opts.check()
+}
+
+// Carve implements Generator.
+func (g GrowingTree) Carve(m *Maze, r *rand.Rand, start Point) error {
+	return m.carveCells(g, r, start)
+}
+
+// wrapSafe implements wrapCarver.
+func (GrowingTree) wrapSafe() {}
+
+// carveGraph implements graphCarver.
+func (g GrowingTree) carveGraph(gr cellGraph, r *rand.Rand, start int) error {
+	if g.Newest < 0 || g.Oldest < 0 || g.Random < 0 {
+		return fmt.Errorf("growing tree weights must not be negative")
+	}
+	total := g.Newest + g.Oldest + g.Random
+	if total == 0 {
+		g.Newest, total = 1, 1
+	}
+	// Only roll for the policy when there is more than one to choose from.
+	mixed := g.Newest != total && g.Oldest != total && g.Random != total
+
+	visited := make([]bool, gr.size())
+	visited[start] = true
+	active := []int{start}
+	// parent records where each cell was entered from, so that the bias can
+	// keep corridors straight no matter which cell is picked.
+	parent := make([]int, gr.size())
+	parent[start] = -1
+
+	for len(active) > 0 {
+		var i int
+		roll := total
+		if mixed {
+			roll = r.Float64() * total
+		}
+		switch {
+		case roll <= g.Newest && g.Newest > 0:
+			i = len(active) - 1
+		case roll <= g.Newest+g.Oldest && g.Oldest > 0:
+			i = 0
+		default:
+			i = r.Intn(len(active))
+		}
+		current := active[i]
+
+		neighbors := unvisited(gr, current, visited)
+		if len(neighbors) == 0 {
+			active = append(active[:i], active[i+1:]...)
+			continue
+		}
+
+		next := chooseNeighbor(gr, r, parent[current], current, neighbors)
+		gr.link(current, next)
+		visited[next] = true
+		parent[next] = current
+		active = append(active, next)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestGrowingTree(t *testing.T) {
+	for _, g := range []maze.GrowingTree{
+		{},
+		{Oldest: 1},
+		{Random: 1},
+		{Newest: 0.75, Random: 0.25},
+		{Newest: 1, Oldest: 1, Random: 1},
+	} {
+		m, err := maze.New(41, 21, 11, 7)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(g); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(3, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error for %+v, but got %v", g, err)
+		}
+		checkPerfectMaze(t, m)
+	}
+
+	checkReproducible(t, maze.GrowingTree{Newest: 0.7, Random: 0.3})
+}
+
+func TestGrowingTreeNewestIsDFS(t *testing.T) {
+	var grids []string
+	for _, g := range []maze.Generator{maze.DFS{}, maze.GrowingTree{Newest: 1}} {
+		m, err := maze.New(31, 21, 7, 5)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(g); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(11, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		grids = append(grids, gridString(m))
+	}
+	if grids[0] != grids[1] {
+		t.Errorf("Expected the newest policy to match DFS, got\n%s\nand\n%s", grids[0], grids[1])
+	}
+}
+
+func TestParseGrowingTree(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected maze.GrowingTree
+	}{
+		{"growingtree", maze.GrowingTree{}},
+		{"growingtree:oldest", maze.GrowingTree{Oldest: 1}},
+		{"GrowingTree:newest=0.7,random=0.3", maze.GrowingTree{Newest: 0.7, Random: 0.3}},
+	}
+	for _, tc := range testCases {
+		g, err := maze.ParseGenerator(tc.spec)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, but got %v", tc.spec, err)
+		}
+		if g != tc.expected {
+			t.Errorf("ParseGenerator(%q) = %+v; want %+v", tc.spec, g, tc.expected)
+		}
+	}
+
+	for _, spec := range []string{"growingtree:newest=-1", "growingtree:middle=1", "growingtree:random=x"} {
+		if _, err := maze.ParseGenerator(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package maze
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// HexLayout is the orientation of the cells of a hexagonal grid.
+type HexLayout int
+
+const (
+	// PointyTop cells have a corner at the top. They are laid out in rows,
+	// and every odd row is shifted half a cell to the right.
+	PointyTop HexLayout = iota
+	// FlatTop cells have a side at the top. They are laid out in columns,
+	// and every odd column is shifted half a cell down.
+	FlatTop
+)
+
+// hexOffsets are the column and row offsets of the six neighbors of a cell,
+// by layout and by whether the cell is in a shifted row or column. The sides
+// run clockwise, from east for PointyTop and from south-east for FlatTop, so
+// that the opposite of side k is side k+3.
+var hexOffsets = map[HexLayout][2][6]Point{
+	PointyTop: {
+		{{1, 0}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}},
+		{{1, 0}, {1, 1}, {0, 1}, {-1, 0}, {0, -1}, {1, -1}},
+	},
+	FlatTop: {
+		{{1, 0}, {0, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}},
+		{{1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {0, -1}, {1, 0}},
+	},
+}
+
+// HexMaze is a maze on a grid of hexagonal cells, where every cell has up to
+// six neighbors. Cells are addressed as a Point by column (X) and row (Y).
+// Like Maze, it can have a den in its center: a room of all the cells within
+// a given number of steps of the center cell, with a single door.
+type HexMaze struct {
+	graphMaze
+	cols      int
+	rows      int
+	layout    HexLayout
+	denRadius int
+}
+
+// NewHex creates a hexagonal maze of cols by rows cells. A denRadius above 0
+// adds a den of the cells up to that many steps from the center cell.
+func NewHex(cols, rows int, layout HexLayout, denRadius int) (*HexMaze, error) {
+	if cols <= 0 || rows <= 0 {
+		return nil, fmt.Errorf("width and height must be positive")
+	}
+	if layout != PointyTop && layout != FlatTop {
+		return nil, fmt.Errorf("invalid hex layout: %d", layout)
+	}
+	if denRadius < 0 {
+		return nil, fmt.Errorf("den radius must be non-negative")
+	}
+
+	h := &HexMaze{graphMaze: graphMaze{graph: newGraph("hexagonal", cols*rows)}, cols: cols, rows: rows, layout: layout, denRadius: denRadius}
+	h.cellAt = func(p Point) int {
+		if !h.contains(p) {
+			return -1
+		}
+		return h.index(p)
+	}
+	h.pointOf = h.point
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			var neighbors []int
+			for _, o := range hexOffsets[layout][h.shift(col, row)] {
+				n := Point{X: col + o.X, Y: row + o.Y}
+				if h.contains(n) {
+					neighbors = append(neighbors, h.index(n))
+				} else {
+					neighbors = append(neighbors, -1)
+				}
+			}
+			h.setNeighbors(h.index(Point{X: col, Y: row}), neighbors...)
+		}
+	}
+	h.ahead = func(prev, cur int) int {
+		return h.adj[cur][h.side(prev, cur)]
+	}
+
+	if denRadius > 0 {
+		// The den and the ring of cells around it must fit in the grid.
+		center := Point{X: cols / 2, Y: rows / 2}
+		around := 0
+		for i := range h.den {
+			d := h.distance(h.point(i), center)
+			h.den[i] = d <= denRadius
+			if d <= denRadius+1 {
+				around++
+			}
+		}
+		if around != hexDiscSize(denRadius+1) {
+			return nil, fmt.Errorf("den radius (%d) is too large for a %dx%d grid", denRadius, cols, rows)
+		}
+	}
+	h.reset()
+	return h, nil
+}
+
+// shift returns 1 if the cell is in a row (PointyTop) or column (FlatTop)
+// that is shifted by half a cell, and 0 otherwise.
+func (h *HexMaze) shift(col, row int) int {
+	if h.layout == PointyTop {
+		return row & 1
+	}
+	return col & 1
+}
+
+// contains reports whether p is a cell of the grid.
+func (h *HexMaze) contains(p Point) bool {
+	return p.X >= 0 && p.X < h.cols && p.Y >= 0 && p.Y < h.rows
+}
+
+// index returns the graph index of cell p.
+func (h *HexMaze) index(p Point) int {
+	return p.Y*h.cols + p.X
+}
+
+// point returns the cell with graph index i.
+func (h *HexMaze) point(i int) Point {
+	return Point{X: i % h.cols, Y: i / h.cols}
+}
+
+// axial converts a cell to axial coordinates, in which the six neighbors of
+// any cell are at the same offsets.
+func (h *HexMaze) axial(p Point) (q, r int) {
+	if h.layout == PointyTop {
+		return p.X - (p.Y-(p.Y&1))/2, p.Y
+	}
+	return p.X, p.Y - (p.X-(p.X&1))/2
+}
+
+// distance returns the number of steps between two cells, ignoring walls.
+func (h *HexMaze) distance(a, b Point) int {
+	aq, ar := h.axial(a)
+	bq, br := h.axial(b)
+	dq, dr := aq-bq, ar-br
+	return (abs(dq) + abs(dr) + abs(dq+dr)) / 2
+}
+
+// hexDiscSize returns the number of cells within radius steps of a cell.
+func hexDiscSize(radius int) int {
+	return 3*radius*(radius+1) + 1
+}
+
+// Width returns the number of columns of the grid.
+func (h *HexMaze) Width() int { return h.cols }
+
+// Height returns the number of rows of the grid.
+func (h *HexMaze) Height() int { return h.rows }
+
+// Layout returns the orientation of the cells.
+func (h *HexMaze) Layout() HexLayout { return h.layout }
+
+// Generate carves a perfect maze with the maze's generator, opens the den's
+// door and places the Start and End. As for Maze, a seed reproduces the maze,
+// and a start or end point that is not given is placed as far as possible
+// from the other. The bias is the chance of carving straight on, for the
+// generators that honour it.
+func (h *HexMaze) Generate(seed int64, start, end *Point, bias float64) error {
+	s, err := h.cell(start, "start", false)
+	if err != nil {
+		return err
+	}
+	e, err := h.cell(end, "end", false)
+	if err != nil {
+		return err
+	}
+	return h.generate(seed, h.Generator(), bias, s, e)
+}
+
+// String renders the maze as text.
+func (h *HexMaze) String() string {
+	return h.Render(nil)
+}
+
+// Render draws the maze as text, with the Start and End markers and the
+// cells of solution marked as SolutionPath. PointyTop cells are drawn as
+//
+//	 / \
+//	|   |
+//	 \ /
+//
+// and FlatTop cells as
+//
+//	 __
+//	/  \
+//	\__/
+//
+// where neighboring cells share their walls, and open passages are blank.
+func (h *HexMaze) Render(solution []Point) string {
+	var width, height int
+	if h.layout == PointyTop {
+		width, height = 4*h.cols+1+2*min(h.rows-1, 1), 2*h.rows+1
+	} else {
+		width, height = 3*h.cols+1, 2*h.rows+1+min(h.cols-1, 1)
+	}
+	canvas := make([][]rune, height)
+	for y := range canvas {
+		canvas[y] = []rune(strings.Repeat(" ", width))
+	}
+
+	for i := range h.adj {
+		p := h.point(i)
+		var x, y int
+		// The character of each side, and its offset from the cell's corner.
+		var sides [6]struct {
+			dx, dy int
+			s      string
+		}
+		if h.layout == PointyTop {
+			x, y = 4*p.X+2*(p.Y&1), 2*p.Y
+			sides = [6]struct {
+				dx, dy int
+				s      string
+			}{{4, 1, "|"}, {3, 2, "/"}, {1, 2, "\\"}, {0, 1, "|"}, {1, 0, "/"}, {3, 0, "\\"}}
+		} else {
+			x, y = 3*p.X, 2*p.Y+(p.X&1)
+			sides = [6]struct {
+				dx, dy int
+				s      string
+			}{{3, 2, "/"}, {1, 2, "__"}, {0, 2, "\\"}, {0, 1, "/"}, {1, 0, "__"}, {3, 1, "\\"}}
+		}
+		for k, side := range sides {
+			if h.linked[i][k] {
+				continue
+			}
+			for j, c := range side.s {
+				canvas[y+side.dy][x+side.dx+j] = c
+			}
+		}
+	}
+
+	mark := func(p Point, c Cell) {
+		if h.layout == PointyTop {
+			canvas[2*p.Y+1][4*p.X+2*(p.Y&1)+2] = rune(c)
+		} else {
+			canvas[2*p.Y+(p.X&1)+1][3*p.X+1] = rune(c)
+		}
+	}
+	for _, p := range solution {
+		mark(p, SolutionPath)
+	}
+	if h.start >= 0 {
+		mark(h.Start(), Start)
+		mark(h.End(), End)
+	}
+
+	lines := make([]string, height)
+	for y, row := range canvas {
+		lines[y] = strings.TrimRight(string(row), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// corners returns the center of cell p and its six corners, where the side
+// k of the cell runs from corner k to corner k+1, for cells whose corners are
+// size apart from their center.
+func (h *HexMaze) corners(p Point, size float64) (svgPoint, [6]svgPoint) {
+	w := math.Sqrt(3) * size
+	var center svgPoint
+	var offset float64
+	if h.layout == PointyTop {
+		center = svgPoint{x: w*(float64(p.X)+0.5*float64(p.Y&1)) + w/2, y: 1.5*size*float64(p.Y) + size}
+		offset = -30
+	} else {
+		center = svgPoint{x: 1.5*size*float64(p.X) + size, y: w*(float64(p.Y)+0.5*float64(p.X&1)) + w/2}
+	}
+	var cs [6]svgPoint
+	for k := range cs {
+		angle := (60*float64(k) + offset) * math.Pi / 180
+		cs[k] = svgPoint{x: center.x + size*math.Cos(angle), y: center.y + size*math.Sin(angle)}
+	}
+	return center, cs
+}
+
+// WriteSVG draws the maze as an SVG image, with cells whose corners are
+// cellSize pixels from their center, and with the cells of solution joined
+// by a line.
+func (h *HexMaze) WriteSVG(w io.Writer, cellSize float64, solution []Point) error {
+	if cellSize <= 0 {
+		return fmt.Errorf("cell size must be positive")
+	}
+	margin := cellSize / 2
+	root3 := math.Sqrt(3)
+	var width, height float64
+	if h.layout == PointyTop {
+		width = root3 * cellSize * (float64(h.cols) + 0.5*float64(min(h.rows-1, 1)))
+		height = cellSize * (1.5*float64(h.rows) + 0.5)
+	} else {
+		width = cellSize * (1.5*float64(h.cols) + 0.5)
+		height = root3 * cellSize * (float64(h.rows) + 0.5*float64(min(h.cols-1, 1)))
+	}
+	shift := func(p svgPoint) svgPoint { return svgPoint{x: p.x + margin, y: p.y + margin} }
+
+	s := &svgWriter{w: w}
+	s.begin(width+2*margin, height+2*margin)
+	for i := range h.adj {
+		if h.den[i] {
+			_, cs := h.corners(h.point(i), cellSize)
+			ps := make([]svgPoint, len(cs))
+			for k, c := range cs {
+				ps[k] = shift(c)
+			}
+			s.polygon(ps, svgDenColor)
+		}
+	}
+	for i := range h.adj {
+		_, cs := h.corners(h.point(i), cellSize)
+		for k, n := range h.adj[i] {
+			// Draw each wall once, from the cell with the lower index.
+			if h.linked[i][k] || (n >= 0 && n < i) {
+				continue
+			}
+			s.wall(shift(cs[k]), shift(cs[(k+1)%6]))
+		}
+	}
+	path := make([]svgPoint, len(solution))
+	for i, p := range solution {
+		center, _ := h.corners(p, cellSize)
+		path[i] = shift(center)
+	}
+	s.solution(path)
+	if h.start >= 0 {
+		start, _ := h.corners(h.Start(), cellSize)
+		end, _ := h.corners(h.End(), cellSize)
+		s.marker(shift(start), cellSize/3, svgStartColor)
+		s.marker(shift(end), cellSize/3, svgEndColor)
+	}
+	return s.end()
+}
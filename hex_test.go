@@ -0,0 +1,234 @@
+package maze_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// checkHexMaze verifies that a hexagonal maze is a perfect maze: every cell
+// outside the den can be reached from the Start by exactly one route, and
+// the den is reached through its door only.
+func checkHexMaze(t *testing.T, h *maze.HexMaze) {
+	t.Helper()
+	start := h.Start()
+	seen := map[maze.Point]bool{start: true}
+	queue := []maze.Point{start}
+	passages := 0
+	for head := 0; head < len(queue); head++ {
+		p := queue[head]
+		for _, n := range h.Neighbors(p) {
+			if !h.Linked(p, n) {
+				continue
+			}
+			if h.IsInsideDen(p) != h.IsInsideDen(n) {
+				if den, cell, _ := h.Door(); !(p == den && n == cell) && !(p == cell && n == den) {
+					t.Fatalf("Expected the den to be entered through its door only, got a passage from %+v to %+v", p, n)
+				}
+			}
+			if !h.IsInsideDen(p) && !h.IsInsideDen(n) {
+				passages++
+			}
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	if len(seen) != h.Width()*h.Height() {
+		t.Fatalf("Expected all %d cells to be reachable, got %d:\n%s", h.Width()*h.Height(), len(seen), h)
+	}
+	cells := 0
+	for y := 0; y < h.Height(); y++ {
+		for x := 0; x < h.Width(); x++ {
+			if !h.IsInsideDen(maze.Point{X: x, Y: y}) {
+				cells++
+			}
+		}
+	}
+	// Each passage was counted from both of its cells.
+	if passages/2 != cells-1 {
+		t.Errorf("Expected %d passages outside the den for a perfect maze, got %d", cells-1, passages/2)
+	}
+}
+
+func TestHexMaze(t *testing.T) {
+	for _, layout := range []maze.HexLayout{maze.PointyTop, maze.FlatTop} {
+		for _, algo := range []string{"dfs", "growingtree:random", "huntandkill", "kruskal", "prim", "wilson", "aldousbroder"} {
+			h, err := maze.NewHex(13, 11, layout, 2)
+			if err != nil {
+				t.Fatalf("Failed to create maze: %v", err)
+			}
+			g, err := maze.ParseGenerator(algo)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", algo, err)
+			}
+			if err := h.SetGenerator(g); err != nil {
+				t.Fatalf("Expected %s to support hexagonal grids, got %v", algo, err)
+			}
+			if err := h.Generate(7, nil, nil, 0.5); err != nil {
+				t.Fatalf("Expected no error with %s, but got %v", algo, err)
+			}
+			checkHexMaze(t, h)
+			if _, _, ok := h.Door(); !ok {
+				t.Errorf("Expected the den of the %s maze to have a door", algo)
+			}
+			if h.IsInsideDen(h.Start()) || h.IsInsideDen(h.End()) {
+				t.Errorf("Expected the Start and End outside the den, got %+v and %+v", h.Start(), h.End())
+			}
+			path, found := h.Solve()
+			if !found || path[0] != h.Start() || path[len(path)-1] != h.End() {
+				t.Fatalf("Expected a route from Start to End with %s, got %v", algo, path)
+			}
+			for i := 1; i < len(path); i++ {
+				if !h.Linked(path[i-1], path[i]) {
+					t.Fatalf("Expected the route to follow passages, but %+v and %+v are not linked", path[i-1], path[i])
+				}
+			}
+		}
+	}
+}
+
+func TestHexMazeNeighbors(t *testing.T) {
+	h, err := maze.NewHex(5, 5, maze.PointyTop, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if n := len(h.Neighbors(maze.Point{X: 2, Y: 2})); n != 6 {
+		t.Errorf("Expected an inner cell to have 6 neighbors, got %d", n)
+	}
+	if n := len(h.Neighbors(maze.Point{X: 0, Y: 0})); n != 2 {
+		t.Errorf("Expected the top left cell to have 2 neighbors, got %d", n)
+	}
+	// Odd rows are shifted to the right, so their cells border the next column up.
+	for _, n := range h.Neighbors(maze.Point{X: 2, Y: 1}) {
+		if n == (maze.Point{X: 1, Y: 0}) {
+			t.Errorf("Expected %+v not to border %+v in a pointy-top grid", n, maze.Point{X: 2, Y: 1})
+		}
+	}
+
+	f, err := maze.NewHex(5, 5, maze.FlatTop, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	found := false
+	for _, n := range f.Neighbors(maze.Point{X: 1, Y: 1}) {
+		found = found || n == (maze.Point{X: 2, Y: 2})
+	}
+	if !found {
+		t.Errorf("Expected odd columns of a flat-top grid to border the next row down")
+	}
+}
+
+func TestHexMazePoints(t *testing.T) {
+	h, err := maze.NewHex(11, 9, maze.FlatTop, 1)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	start, end := maze.Point{X: 0, Y: 0}, maze.Point{X: 10, Y: 8}
+	if err := h.Generate(3, &start, &end, 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if h.Start() != start || h.End() != end {
+		t.Errorf("Expected Start %+v and End %+v, got %+v and %+v", start, end, h.Start(), h.End())
+	}
+	checkHexMaze(t, h)
+
+	inside := maze.Point{X: 5, Y: 4}
+	if err := h.Generate(3, &inside, nil, 0.5); err == nil {
+		t.Errorf("Expected an error for a start point inside the den")
+	}
+	outside := maze.Point{X: 11, Y: 0}
+	if err := h.Generate(3, &outside, nil, 0.5); err == nil {
+		t.Errorf("Expected an error for a start point outside the grid")
+	}
+}
+
+func TestHexMazeReproducible(t *testing.T) {
+	render := func(seed int64) string {
+		h, err := maze.NewHex(9, 9, maze.PointyTop, 1)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := h.Generate(seed, nil, nil, 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		return h.String()
+	}
+	if render(42) != render(42) {
+		t.Errorf("Expected the same seed to give the same maze")
+	}
+	if render(42) == render(43) {
+		t.Errorf("Expected different seeds to give different mazes")
+	}
+}
+
+func TestHexMazeErrors(t *testing.T) {
+	if _, err := maze.NewHex(0, 5, maze.PointyTop, 0); err == nil {
+		t.Errorf("Expected an error for a grid without columns")
+	}
+	if _, err := maze.NewHex(7, 7, maze.PointyTop, 3); err == nil {
+		t.Errorf("Expected an error for a den that does not fit")
+	}
+	h, err := maze.NewHex(7, 7, maze.PointyTop, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	for _, g := range []maze.Generator{maze.BinaryTree{}, maze.Sidewinder{}, maze.Eller{}, maze.Division{}, maze.Cave{}} {
+		if err := h.SetGenerator(g); err == nil || !strings.Contains(err.Error(), "hexagonal") {
+			t.Errorf("Expected %T to be rejected for a hexagonal grid, got %v", g, err)
+		}
+	}
+}
+
+func TestHexMazeRender(t *testing.T) {
+	for _, layout := range []maze.HexLayout{maze.PointyTop, maze.FlatTop} {
+		h, err := maze.NewHex(4, 3, layout, 0)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		closed := h.String()
+		if strings.ContainsAny(closed, "SE") {
+			t.Errorf("Expected no markers before generation, got:\n%s", closed)
+		}
+		if err := h.Generate(1, nil, nil, 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		path, _ := h.Solve()
+		text := h.Render(path)
+		if strings.Count(text, "S") != 1 || strings.Count(text, "E") != 1 || strings.Count(text, ".") != len(path)-2 {
+			t.Errorf("Expected one Start, one End and %d solution cells, got:\n%s", len(path)-2, text)
+		}
+		// Opening the passages removes walls but never moves them.
+		if strings.Count(text, "\n") != strings.Count(closed, "\n") {
+			t.Errorf("Expected the maze drawn on the same lines as the closed grid, got:\n%s\nand:\n%s", closed, text)
+		}
+
+		var buf bytes.Buffer
+		if err := h.WriteSVG(&buf, 10, path); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		svg := buf.String()
+		if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+			t.Errorf("Expected an SVG document, got:\n%s", svg)
+		}
+		// Every side of every cell is a wall, except the 11 passages of a
+		// perfect maze of 12 cells.
+		border, inner := 0, 0
+		for y := 0; y < h.Height(); y++ {
+			for x := 0; x < h.Width(); x++ {
+				n := len(h.Neighbors(maze.Point{X: x, Y: y}))
+				border, inner = border+6-n, inner+n
+			}
+		}
+		sides := border + inner/2
+		if walls := strings.Count(svg, "<line"); walls != sides-11 {
+			t.Errorf("Expected %d walls, got %d", sides-11, walls)
+		}
+		if !strings.Contains(svg, "<polyline") || strings.Count(svg, "<circle") != 2 {
+			t.Errorf("Expected a solution line and two markers, got:\n%s", svg)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package maze
+
+import "math/rand"
+
+// HuntAndKill carves the maze with the hunt-and-kill algorithm.
+// It walks randomly from the start point, carving as it goes, until it reaches
+// a cell with no unvisited neighbors. It then hunts, scanning the maze row by
+// row for the first unvisited cell next to a visited one, links the two and
+// starts a new walk from there. Like DFS it produces long corridors, and the
+// maze bias is applied in the same way while walking.
+type HuntAndKill struct{}
+
+// Carve implements Generator.
+func (h HuntAndKill) Carve(m *Maze, r *rand.Rand, start Point) error {
+	return m.carveCells(h, r, start)
+}
+
+// wrapSafe implements wrapCarver.
+func (HuntAndKill) wrapSafe() {}
+
+// carveGraph implements graphCarver.
+func (HuntAndKill) carveGraph(g cellGraph, r *rand.Rand, start int) error {
+	cells := g.cells()
+	visited := make([]bool, g.size())
+	hunted := 0 // Every cell before this index has been visited.
+
+	previous, current := -1, start
+	visited[current] = true
+	for {
+		// Kill: walk until the walk gets stuck.
+		if neighbors := unvisited(g, current, visited); len(neighbors) > 0 {
+			next := chooseNeighbor(g, r, previous, current, neighbors)
+			g.link(current, next)
+			visited[next] = true
+			previous, current = current, next
+			continue
+		}
+
+		// Hunt: find an unvisited cell that borders the visited part of the maze.
+		for hunted < len(cells) && visited[cells[hunted]] {
+			hunted++
+		}
+		found := false
+		for _, c := range cells[hunted:] {
+			if visited[c] {
+				continue
+			}
+			var neighbors []int
+			for _, n := range g.neighbors(c) {
+				if visited[n] {
+					neighbors = append(neighbors, n)
+				}
+			}
+			if len(neighbors) > 0 {
+				previous, current = g.pick(r, -1, c, neighbors), c
+				g.link(previous, current)
+				visited[current] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestHuntAndKill(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		m, err := maze.New(41, 21, 11, 7)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.HuntAndKill{}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+	}
+
+	checkReproducible(t, maze.HuntAndKill{})
+}
@@ -0,0 +1,84 @@
+package maze
+
+import "math/rand"
+
+// Kruskal carves the maze with randomized Kruskal's algorithm.
+// Every wall between two cells is considered once, in random order, and is
+// removed whenever it separates two cells that are not yet connected.
+// The result has many short dead ends. The start point and the maze bias are ignored.
+type Kruskal struct{}
+
+// Carve implements Generator.
+func (k Kruskal) Carve(m *Maze, r *rand.Rand, start Point) error {
+	return m.carveCells(k, r, start)
+}
+
+// wrapSafe implements wrapCarver.
+func (Kruskal) wrapSafe() {}
+
+// carveGraph implements graphCarver.
+func (Kruskal) carveGraph(g cellGraph, r *rand.Rand, start int) error {
+	// Collect each edge once, from its lower-numbered cell.
+	var edges [][2]int
+	for _, c := range g.cells() {
+		for _, n := range g.neighbors(c) {
+			if c < n {
+				edges = append(edges, [2]int{c, n})
+			}
+		}
+	}
+	// A passage has no direction, so it weighs the average of both ways along it.
+	var weight func(e [2]int) float64
+	if g.weighted() {
+		weight = func(e [2]int) float64 { return (g.weight(e[0], e[1]) + g.weight(e[1], e[0])) / 2 }
+	}
+	shuffleEdges(r, edges, weight)
+
+	sets := newUnionFind(g.size())
+	for _, e := range edges {
+		if sets.union(e[0], e[1]) {
+			g.link(e[0], e[1])
+		}
+	}
+	return nil
+}
+
+// unionFind is a disjoint-set forest with path compression and union by size.
+type unionFind struct {
+	parent []int
+	size   []int
+}
+
+// newUnionFind creates n singleton sets.
+func newUnionFind(n int) *unionFind {
+	u := &unionFind{parent: make([]int, n), size: make([]int, n)}
+	for i := range u.parent {
+		u.parent[i] = i
+		u.size[i] = 1
+	}
+	return u
+}
+
+// find returns the representative of the set containing i.
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union merges the sets containing a and b.
+// It returns false if they were already the same set.
+func (u *unionFind) union(a, b int) bool {
+	ra, rb := u.find(a), u.find(b)
+	if ra == rb {
+		return false
+	}
+	if u.size[ra] < u.size[rb] {
+		ra, rb = rb, ra
+	}
+	u.parent[rb] = ra
+	u.size[ra] += u.size[rb]
+	return true
+}
@@ -0,0 +1,169 @@
+package maze
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mask marks parts of a maze as out of bounds, so that mazes can take any shape.
+// A mask does not need to match the size of the maze it is applied to:
+// it is stretched over the whole grid.
+type Mask struct {
+	width   int
+	height  int
+	blocked []bool
+}
+
+// NewMask creates a mask of the given size with every cell in bounds.
+func NewMask(width, height int) (*Mask, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("mask width and height must be positive")
+	}
+	return &Mask{width: width, height: height, blocked: make([]bool, width*height)}, nil
+}
+
+// Width returns the mask's width.
+func (k *Mask) Width() int {
+	return k.width
+}
+
+// Height returns the mask's height.
+func (k *Mask) Height() int {
+	return k.height
+}
+
+// Blocked reports whether a cell of the mask is out of bounds.
+// Cells outside the mask are always out of bounds.
+func (k *Mask) Blocked(x, y int) bool {
+	if x < 0 || x >= k.width || y < 0 || y >= k.height {
+		return true
+	}
+	return k.blocked[y*k.width+x]
+}
+
+// SetBlocked marks a cell of the mask as out of bounds or in bounds.
+// It returns false if the cell is outside the mask.
+func (k *Mask) SetBlocked(x, y int, blocked bool) bool {
+	if x < 0 || x >= k.width || y < 0 || y >= k.height {
+		return false
+	}
+	k.blocked[y*k.width+x] = blocked
+	return true
+}
+
+// ParseMask reads a mask drawn as ASCII art, one text line per row.
+// Spaces and dots are out of bounds and any other character is in bounds,
+// so the shape of the maze is drawn with, for example, '#'.
+// Lines shorter than the longest one are padded with out-of-bounds cells.
+func ParseMask(r io.Reader) (*Mask, error) {
+	var lines [][]rune
+	width := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := []rune(strings.TrimRight(scanner.Text(), "\r"))
+		width = max(width, len(line))
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// Trailing empty lines carry no shape.
+	for len(lines) > 0 && len(strings.TrimSpace(string(lines[len(lines)-1]))) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 || width == 0 {
+		return nil, fmt.Errorf("mask is empty")
+	}
+
+	k, err := NewMask(width, len(lines))
+	if err != nil {
+		return nil, err
+	}
+	for y, line := range lines {
+		for x := 0; x < width; x++ {
+			k.SetBlocked(x, y, x >= len(line) || line[x] == ' ' || line[x] == '.')
+		}
+	}
+	return k, nil
+}
+
+// DecodeMaskImage reads a mask from a PNG image, one cell per pixel.
+// Dark pixels are in bounds; light and transparent pixels are out of bounds,
+// so the shape of the maze is drawn in black on white.
+func DecodeMaskImage(r io.Reader) (*Mask, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode mask image: %w", err)
+	}
+	return maskFromImage(img)
+}
+
+// maskFromImage converts an image to a mask by thresholding its gray level.
+func maskFromImage(img image.Image) (*Mask, error) {
+	bounds := img.Bounds()
+	k, err := NewMask(bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return nil, err
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.At(x, y)
+			_, _, _, alpha := c.RGBA()
+			gray := color.GrayModel.Convert(c).(color.Gray)
+			k.SetBlocked(x-bounds.Min.X, y-bounds.Min.Y, alpha < 0x8000 || gray.Y >= 0x80)
+		}
+	}
+	return k, nil
+}
+
+// LoadMask reads a mask from a file: a PNG image if the name ends in ".png",
+// ASCII art otherwise.
+func LoadMask(path string) (*Mask, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".png") {
+		return DecodeMaskImage(f)
+	}
+	return ParseMask(f)
+}
+
+// SetMask applies a mask to the maze. The mask is stretched over the whole grid,
+// and the cells it marks as out of bounds stay walls that no generator, search or
+// solver crosses. The mask must not cover the den. A nil mask removes the mask.
+func (m *Maze) SetMask(k *Mask) error {
+	previous := m.mask
+	m.mask = k
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			if p := (Point{X: x, Y: y}); m.IsMasked(p) && m.IsInsideDen(p) {
+				m.mask = previous
+				return fmt.Errorf("mask covers the den at %+v", p)
+			}
+		}
+	}
+	return nil
+}
+
+// Mask returns the maze's mask, or nil if it has none.
+func (m *Maze) Mask() *Mask {
+	return m.mask
+}
+
+// IsMasked checks if a given point is out of bounds because of the maze's mask.
+func (m *Maze) IsMasked(p Point) bool {
+	if m.mask == nil {
+		return false
+	}
+	return m.mask.Blocked(p.X*m.mask.width/m.width, p.Y*m.mask.height/m.height)
+}
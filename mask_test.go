@@ -0,0 +1,172 @@
+package maze_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// diamond is an ASCII mask of a diamond shape.
+const diamond = `
+....#....
+...###...
+..#####..
+.#######.
+#########
+.#######.
+..#####..
+...###...
+....#....
+`
+
+func TestParseMask(t *testing.T) {
+	k, err := maze.ParseMask(strings.NewReader(strings.TrimPrefix(diamond, "\n")))
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if k.Width() != 9 || k.Height() != 9 {
+		t.Fatalf("Expected a 9x9 mask, got %dx%d", k.Width(), k.Height())
+	}
+	testCases := []struct {
+		x, y     int
+		expected bool
+	}{
+		{4, 4, false},
+		{4, 0, false},
+		{0, 0, true},
+		{8, 8, true},
+		{-1, 4, true},
+		{9, 4, true},
+	}
+	for _, tc := range testCases {
+		if got := k.Blocked(tc.x, tc.y); got != tc.expected {
+			t.Errorf("Blocked(%d, %d) = %v; want %v", tc.x, tc.y, got, tc.expected)
+		}
+	}
+
+	if _, err := maze.ParseMask(strings.NewReader("\n\n")); err == nil {
+		t.Error("Expected error for an empty mask, but got nil")
+	}
+}
+
+func TestDecodeMaskImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.Black)
+	img.Set(1, 0, color.White)
+	img.Set(2, 0, color.NRGBA{A: 0}) // Transparent.
+	img.Set(3, 0, color.Gray{Y: 0x20})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	k, err := maze.DecodeMaskImage(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	for x, expected := range []bool{false, true, true, false} {
+		if got := k.Blocked(x, 0); got != expected {
+			t.Errorf("Blocked(%d, 0) = %v; want %v", x, got, expected)
+		}
+	}
+
+	if _, err := maze.DecodeMaskImage(strings.NewReader("not a png")); err == nil {
+		t.Error("Expected error for an invalid image, but got nil")
+	}
+}
+
+func TestLoadMask(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diamond.txt")
+	if err := os.WriteFile(path, []byte(diamond), 0o644); err != nil {
+		t.Fatalf("Failed to write mask: %v", err)
+	}
+	k, err := maze.LoadMask(path)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if k.Blocked(4, 5) {
+		t.Error("Expected the centre of the diamond to be in bounds")
+	}
+
+	if _, err := maze.LoadMask(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("Expected error for a missing file, but got nil")
+	}
+}
+
+func TestMaskedGeneration(t *testing.T) {
+	k, err := maze.ParseMask(strings.NewReader(diamond))
+	if err != nil {
+		t.Fatalf("Failed to parse mask: %v", err)
+	}
+
+	for _, g := range []maze.Generator{
+		maze.DFS{}, maze.Kruskal{}, maze.Prim{}, maze.Wilson{}, maze.AldousBroder{}, maze.Eller{},
+		maze.Division{MinChamber: 2}, maze.GrowingTree{Random: 1}, maze.HuntAndKill{}, maze.BinaryTree{}, maze.Sidewinder{},
+	} {
+		m, err := maze.New(41, 41, 5, 5)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetMask(k); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if err := m.SetGenerator(g); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(3, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error for %T, but got %v", g, err)
+		}
+
+		for y := 0; y < m.Height(); y++ {
+			for x := 0; x < m.Width(); x++ {
+				if cell, _ := m.Cell(x, y); cell != maze.Wall && m.IsMasked(maze.Point{X: x, Y: y}) {
+					t.Fatalf("%T carved masked cell %d,%d:\n%s", g, x, y, gridString(m))
+				}
+			}
+		}
+		if m.IsMasked(m.Start()) || m.IsMasked(m.End()) {
+			t.Errorf("%T placed Start %+v or End %+v outside the mask", g, m.Start(), m.End())
+		}
+		if _, found := m.Solve(); !found {
+			t.Errorf("%T: masked maze should be solvable, but it is not", g)
+		}
+	}
+}
+
+func TestSetMask(t *testing.T) {
+	m, err := maze.New(21, 21, 5, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+
+	// Block the centre of the maze, where the den is.
+	k, err := maze.NewMask(3, 3)
+	if err != nil {
+		t.Fatalf("Failed to create mask: %v", err)
+	}
+	k.SetBlocked(1, 1, true)
+	if err := m.SetMask(k); err == nil {
+		t.Error("Expected error for a mask covering the den, but got nil")
+	}
+	if m.Mask() != nil {
+		t.Error("Expected a rejected mask not to be applied")
+	}
+
+	// Block the top-left corner instead.
+	k.SetBlocked(1, 1, false)
+	k.SetBlocked(0, 0, true)
+	if err := m.SetMask(k); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	start := maze.Point{X: 1, Y: 1}
+	if err := m.Generate(1, &start, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error for a start point outside the mask, but got nil")
+	}
+}
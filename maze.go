@@ -18,6 +18,12 @@ const (
 	End Cell = 'E'
 	// SolutionPath is a cell on the solved path.
 	SolutionPath Cell = '.'
+	// StairUp is a cell of a Maze3D with stairs up to the level above.
+	StairUp Cell = '↑'
+	// StairDown is a cell of a Maze3D with stairs down to the level below.
+	StairDown Cell = '↓'
+	// StairUpDown is a cell of a Maze3D with stairs both up and down.
+	StairUpDown Cell = '↕'
 )
 
 // Point represents a coordinate in the maze.
@@ -32,13 +38,24 @@ type Maze struct {
 	grid   [][]Cell
 	start  Point
 	end    Point
-	door   Point
-
-	// den dimensions
-	denWidth  int
-	denHeight int
-	denStartX int
-	denStartY int
+	doors  [][]Point
+
+	// generation settings
+	generator Generator
+	bias      float64
+	braid     float64
+	braided   int
+	weights   DirectionWeights
+	biasMap   BiasMap
+	mask      *Mask
+	entrance  *Opening
+	exit      *Opening
+	placement Placement
+	placed    PlacementResult
+	wrap      Wrap
+
+	// dens are the rooms of the maze; the first one is the central den created by New.
+	dens []Den
 }
 
 // adjustToOdd ensures a dimension is odd by incrementing it if it's even and positive.
@@ -101,14 +118,13 @@ func New(width, height, denWidth, denHeight int) (*Maze, error) {
 	}
 
 	m := &Maze{
-		width:     adjWidth,
-		height:    adjHeight,
-		denWidth:  adjDenWidth,
-		denHeight: adjDenHeight,
+		width:  adjWidth,
+		height: adjHeight,
 	}
 
-	if m.denWidth > 0 && m.denHeight > 0 {
-		m.denStartX, m.denStartY = calculateDenPosition(m.width, m.denWidth, m.height, m.denHeight)
+	if adjDenWidth > 0 && adjDenHeight > 0 {
+		denStartX, denStartY := calculateDenPosition(m.width, adjDenWidth, m.height, adjDenHeight)
+		m.dens = []Den{{X: denStartX, Y: denStartY, Width: adjDenWidth, Height: adjDenHeight}}
 	}
 
 	m.initializeGrid()
@@ -116,7 +132,7 @@ func New(width, height, denWidth, denHeight int) (*Maze, error) {
 	return m, nil
 }
 
-// initializeGrid creates the grid and carves out the den area.
+// initializeGrid creates the grid and carves out the den areas.
 func (m *Maze) initializeGrid() {
 	m.grid = make([][]Cell, m.height)
 	for i := range m.grid {
@@ -133,32 +149,41 @@ func (m *Maze) initializeGrid() {
 
 }
 
-// IsInsideDen checks if a given point is within the boundaries of the central den.
+// IsInsideDen checks if a given point is within the boundaries of any den.
 func (m *Maze) IsInsideDen(p Point) bool {
-	if m.denWidth <= 0 || m.denHeight <= 0 {
-		return false
-	}
-	return p.X >= m.denStartX && p.X < m.denStartX+m.denWidth &&
-		p.Y >= m.denStartY && p.Y < m.denStartY+m.denHeight
+	return m.denAt(p) >= 0
 }
 
-// IsAdjacentToDen checks if a point is directly next to a den cell, but not inside it.
+// IsAdjacentToDen checks if a point is directly next to a den cell, but not inside a den.
 func (m *Maze) IsAdjacentToDen(p Point) bool {
-	if m.denWidth <= 0 || m.denHeight <= 0 {
-		return false
+	return m.denNextTo(p) >= 0
+}
+
+// denAt returns the index of the den that contains a point, or -1 if there is none.
+func (m *Maze) denAt(p Point) int {
+	for i, d := range m.dens {
+		if d.contains(p) {
+			return i
+		}
 	}
+	return -1
+}
+
+// denNextTo returns the index of the den that a point is directly next to,
+// or -1 if there is none or the point is inside a den.
+func (m *Maze) denNextTo(p Point) int {
 	// A point inside the den is not considered "adjacent".
 	if m.IsInsideDen(p) {
-		return false
+		return -1
 	}
 
 	// Check the four cardinal neighbors of the point.
 	for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
-		if m.IsInsideDen(Point{X: p.X + dir.X, Y: p.Y + dir.Y}) {
-			return true
+		if i := m.denAt(Point{X: p.X + dir.X, Y: p.Y + dir.Y}); i >= 0 {
+			return i
 		}
 	}
-	return false
+	return -1
 }
 
 // Start returns the maze's starting point.
@@ -181,29 +206,62 @@ func (m *Maze) Height() int {
 	return m.height
 }
 
-// DenWidth returns the maze's den width.
+// DenWidth returns the width of the maze's first den, or 0 if it has none.
 func (m *Maze) DenWidth() int {
-	return m.denWidth
+	return m.firstDen().Width
 }
 
-// DenHeight returns the maze's den height.
+// DenHeight returns the height of the maze's first den, or 0 if it has none.
 func (m *Maze) DenHeight() int {
-	return m.denHeight
+	return m.firstDen().Height
 }
 
-// DenStartX returns the maze's den startX
+// DenStartX returns the maze's first den startX
 func (m *Maze) DenStartX() int {
-	return m.denStartX
+	return m.firstDen().X
 }
 
-// DenStartY returns the maze's den startY
+// DenStartY returns the maze's first den startY
 func (m *Maze) DenStartY() int {
-	return m.denStartY
+	return m.firstDen().Y
+}
+
+// firstDen returns the maze's first den, or a zero Den if it has none.
+func (m *Maze) firstDen() Den {
+	if len(m.dens) == 0 {
+		return Den{}
+	}
+	return m.dens[0]
 }
 
-// Door returns the maze's den door point.
+// Door returns the first door point of the maze's first den.
+// Use DenDoors or Doors for dens with several doors.
 func (m *Maze) Door() Point {
-	return m.door
+	if len(m.doors) == 0 || len(m.doors[0]) == 0 {
+		return Point{}
+	}
+	return m.doors[0][0]
+}
+
+// Bias returns the straight-corridor bias of the current generation.
+func (m *Maze) Bias() float64 {
+	return m.bias
+}
+
+// Generator returns the algorithm used by Generate to carve the maze.
+func (m *Maze) Generator() Generator {
+	if m.generator == nil {
+		return DFS{}
+	}
+	return m.generator
+}
+
+// SetGenerator selects the algorithm used by Generate to carve the maze.
+// A nil generator restores the default DFS. Every generator can carve a
+// square maze, so unlike on the other grids the error is always nil.
+func (m *Maze) SetGenerator(g Generator) error {
+	m.generator = g
+	return nil
 }
 
 // Cell returns the cell type at a given coordinate.
@@ -214,3 +272,13 @@ func (m *Maze) Cell(x, y int) (Cell, bool) {
 	}
 	return m.grid[y][x], true
 }
+
+// SetCell sets the cell type at a given coordinate.
+// It is meant for custom generators; it returns false if the point is out of bounds.
+func (m *Maze) SetCell(x, y int, c Cell) bool {
+	if x < 0 || x >= m.width || y < 0 || y >= m.height {
+		return false
+	}
+	m.grid[y][x] = c
+	return true
+}
@@ -0,0 +1,232 @@
+package maze
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Point3 represents a coordinate in a 3D maze: a point on one of its levels.
+type Point3 struct {
+	X, Y, Z int
+}
+
+// Maze3D is a maze of several levels stacked on top of each other, like the
+// floors of a dungeon. Every level is a grid like that of Maze, with cells at
+// odd coordinates and walls in between, and a cell can also have stairs to the
+// same cell of the level above or below. Level 0 is the lowest. The Start
+// and End are drawn over any stairs of their cells.
+type Maze3D struct {
+	cells  graphMaze
+	width  int
+	height int
+	depth  int
+	levels [][][]Cell
+}
+
+// NewMaze3D creates a 3D maze of depth levels, each width by height.
+// Like New, it adjusts the width and height to be odd.
+func NewMaze3D(width, height, depth int) (*Maze3D, error) {
+	if width <= 0 || height <= 0 || depth <= 0 {
+		return nil, fmt.Errorf("width, height and depth must be positive")
+	}
+	m := &Maze3D{width: adjustToOdd(width), height: adjustToOdd(height), depth: depth}
+	cols, rows := (m.width-1)/2, (m.height-1)/2
+	if cols == 0 || rows == 0 {
+		return nil, fmt.Errorf("width and height must be at least 3")
+	}
+
+	g := newGraph("3D", cols*rows*depth)
+	index := func(x, y, z int) int {
+		if x < 0 || x >= cols || y < 0 || y >= rows || z < 0 || z >= depth {
+			return -1
+		}
+		return (z*rows+y)*cols + x
+	}
+	// The sides of a cell are east, west, south, north, up and down, so that
+	// the opposite of side k is side k^1.
+	for z := 0; z < depth; z++ {
+		for y := 0; y < rows; y++ {
+			for x := 0; x < cols; x++ {
+				g.setNeighbors(index(x, y, z), index(x+1, y, z), index(x-1, y, z), index(x, y+1, z), index(x, y-1, z), index(x, y, z+1), index(x, y, z-1))
+			}
+		}
+	}
+	// Corridors run straight on, but stairs never lead straight on to more stairs.
+	g.ahead = func(prev, cur int) int {
+		if k := g.side(prev, cur); k < 4 {
+			return g.adj[cur][k]
+		}
+		return -1
+	}
+	// Cells are addressed as a Point3 rather than a Point, so the maze does
+	// not need the graphMaze's conversions.
+	m.cells = graphMaze{graph: g}
+	m.paint()
+	return m, nil
+}
+
+// point returns the coordinate of graph cell c.
+func (m *Maze3D) point(c int) Point3 {
+	cols, rows := (m.width-1)/2, (m.height-1)/2
+	return Point3{X: 2*(c%cols) + 1, Y: 2*(c/cols%rows) + 1, Z: c / (cols * rows)}
+}
+
+// index returns the graph cell at p, which must be a cell of the maze.
+func (m *Maze3D) index(p Point3) int {
+	cols, rows := (m.width-1)/2, (m.height-1)/2
+	return (p.Z*rows+p.Y/2)*cols + p.X/2
+}
+
+// Width returns the width of each level.
+func (m *Maze3D) Width() int { return m.width }
+
+// Height returns the height of each level.
+func (m *Maze3D) Height() int { return m.height }
+
+// Depth returns the number of levels.
+func (m *Maze3D) Depth() int { return m.depth }
+
+// SetGenerator sets the algorithm used by Generate. A nil generator restores
+// the default, DFS. Only the generators that grow from cell to cell (see
+// GeneratorNames) can carve a 3D maze.
+func (m *Maze3D) SetGenerator(g Generator) error {
+	return m.cells.SetGenerator(g)
+}
+
+// Generator returns the algorithm used by Generate.
+func (m *Maze3D) Generator() Generator {
+	return m.cells.Generator()
+}
+
+// Generate carves a perfect maze through all levels with the maze's
+// generator and places the Start and End. As for Maze, a seed reproduces the
+// maze, and a start or end point that is not given is placed as far as
+// possible from the other. The bias is the chance of a corridor going
+// straight on, for the generators that honour it.
+func (m *Maze3D) Generate(seed int64, start, end *Point3, bias float64) error {
+	s, e := -1, -1
+	for _, given := range []struct {
+		p         *Point3
+		index     *int
+		pointType string
+	}{{start, &s, "start"}, {end, &e, "end"}} {
+		if given.p == nil {
+			continue
+		}
+		p := *given.p
+		if p.X <= 0 || p.X >= m.width-1 || p.Y <= 0 || p.Y >= m.height-1 || p.X%2 == 0 || p.Y%2 == 0 || p.Z < 0 || p.Z >= m.depth {
+			return fmt.Errorf("invalid %s point: %+v. must be within maze bounds and have odd coordinates", given.pointType, p)
+		}
+		*given.index = m.index(p)
+	}
+	if err := m.cells.generate(seed, m.Generator(), bias, s, e); err != nil {
+		return err
+	}
+	m.paint()
+	return nil
+}
+
+// paint draws the passages and stairs of the graph into the levels' grids.
+func (m *Maze3D) paint() {
+	m.levels = make([][][]Cell, m.depth)
+	for z := range m.levels {
+		m.levels[z] = make([][]Cell, m.height)
+		for y := range m.levels[z] {
+			m.levels[z][y] = []Cell(strings.Repeat(string(Wall), m.width))
+		}
+	}
+	g := m.cells.graph
+	for c := range g.adj {
+		p := m.point(c)
+		grid := m.levels[p.Z]
+		grid[p.Y][p.X] = Path
+		// Open the walls to the east and south; the others are opened from the
+		// cells on their other side.
+		if g.linked[c][0] {
+			grid[p.Y][p.X+1] = Path
+		}
+		if g.linked[c][2] {
+			grid[p.Y+1][p.X] = Path
+		}
+		switch up, down := g.linked[c][4], g.linked[c][5]; {
+		case up && down:
+			grid[p.Y][p.X] = StairUpDown
+		case up:
+			grid[p.Y][p.X] = StairUp
+		case down:
+			grid[p.Y][p.X] = StairDown
+		}
+	}
+	if g.start >= 0 {
+		start, end := m.Start(), m.End()
+		m.levels[start.Z][start.Y][start.X] = Start
+		m.levels[end.Z][end.Y][end.X] = End
+	}
+}
+
+// Start returns the maze's starting point.
+func (m *Maze3D) Start() Point3 { return m.point(m.cells.start) }
+
+// End returns the maze's ending point.
+func (m *Maze3D) End() Point3 { return m.point(m.cells.end) }
+
+// Cell returns the cell type at a given coordinate.
+// It returns the cell and true if the point is within bounds, otherwise it returns a zero value and false.
+func (m *Maze3D) Cell(x, y, z int) (Cell, bool) {
+	if x < 0 || x >= m.width || y < 0 || y >= m.height || z < 0 || z >= m.depth {
+		return 0, false
+	}
+	return m.levels[z][y][x], true
+}
+
+// Solve finds the shortest path from Start to End and returns it as a slice
+// of points, which includes the openings between cells like that of
+// Maze.Solve, and goes from one level to the next by the stairs.
+// It returns the path and true if a path is found, otherwise it returns nil and false.
+func (m *Maze3D) Solve() ([]Point3, bool) {
+	cells, found := m.cells.solve()
+	if !found {
+		return nil, false
+	}
+	path := []Point3{m.point(cells[0])}
+	for _, c := range cells[1:] {
+		prev, next := path[len(path)-1], m.point(c)
+		if prev.Z == next.Z {
+			path = append(path, Point3{X: (prev.X + next.X) / 2, Y: (prev.Y + next.Y) / 2, Z: prev.Z})
+		}
+		path = append(path, next)
+	}
+	return path, true
+}
+
+// String renders the maze as text, one level after the other.
+func (m *Maze3D) String() string {
+	return m.Render(nil)
+}
+
+// Render draws the maze as text, one level after the other from level 0 up,
+// each under a heading and separated by a blank line. The open cells of
+// solution are marked as SolutionPath.
+func (m *Maze3D) Render(solution []Point3) string {
+	onPath := make(map[Point3]bool, len(solution))
+	for _, p := range solution {
+		onPath[p] = true
+	}
+	var sb strings.Builder
+	for z, grid := range m.levels {
+		if z > 0 {
+			sb.WriteRune('\n')
+		}
+		fmt.Fprintf(&sb, "Level %d:\n", z)
+		for y, row := range grid {
+			for x, cell := range row {
+				if cell == Path && onPath[Point3{X: x, Y: y, Z: z}] {
+					cell = SolutionPath
+				}
+				sb.WriteRune(rune(cell))
+			}
+			sb.WriteRune('\n')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,173 @@
+package maze_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// open reports whether a cell of a 3D maze can be walked on.
+func open(m *maze.Maze3D, p maze.Point3) bool {
+	c, ok := m.Cell(p.X, p.Y, p.Z)
+	return ok && c != maze.Wall
+}
+
+// checkMaze3D verifies that every cell of a 3D maze is open, that stairs up
+// lead to stairs down, and that there are no more passages than a perfect
+// maze has.
+func checkMaze3D(t *testing.T, m *maze.Maze3D) {
+	t.Helper()
+	cells, passages, stairs := 0, 0, 0
+	for z := 0; z < m.Depth(); z++ {
+		for y := 0; y < m.Height(); y++ {
+			for x := 0; x < m.Width(); x++ {
+				p := maze.Point3{X: x, Y: y, Z: z}
+				if !open(m, p) {
+					continue
+				}
+				if x%2 == 1 && y%2 == 1 {
+					cells++
+				} else {
+					passages++
+				}
+				c, _ := m.Cell(x, y, z)
+				up := c == maze.StairUp || c == maze.StairUpDown
+				if up {
+					stairs++
+					// The Start and End cover any stairs of their cells.
+					if above, _ := m.Cell(x, y, z+1); above != maze.StairDown && above != maze.StairUpDown && above != maze.Start && above != maze.End {
+						t.Fatalf("Expected stairs up at %+v to lead to stairs down, got %q", p, above)
+					}
+				}
+			}
+		}
+	}
+	if want := (m.Width() - 1) / 2 * (m.Height() - 1) / 2 * m.Depth(); cells != want {
+		t.Fatalf("Expected %d open cells, got %d", want, cells)
+	}
+	// The count falls short by the stairs the Start and End cover.
+	if passages+stairs > cells-1 {
+		t.Errorf("Expected at most %d passages and stairs for a perfect maze, got %d", cells-1, passages+stairs)
+	}
+}
+
+func TestMaze3D(t *testing.T) {
+	for _, algo := range []string{"dfs", "growingtree:random", "huntandkill", "kruskal", "prim", "wilson", "aldousbroder"} {
+		m, err := maze.NewMaze3D(11, 9, 3)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		g, err := maze.ParseGenerator(algo)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", algo, err)
+		}
+		if err := m.SetGenerator(g); err != nil {
+			t.Fatalf("Expected %s to support 3D grids, got %v", algo, err)
+		}
+		if err := m.Generate(7, nil, nil, 0.5); err != nil {
+			t.Fatalf("Expected no error with %s, but got %v", algo, err)
+		}
+		checkMaze3D(t, m)
+
+		path, found := m.Solve()
+		if !found || path[0] != m.Start() || path[len(path)-1] != m.End() {
+			t.Fatalf("Expected a route from Start to End with %s, got %v", algo, path)
+		}
+		// Each step of the route moves to an adjacent open point, or takes the
+		// stairs to the same point of the next level.
+		visited := map[maze.Point3]bool{path[0]: true}
+		for i := 1; i < len(path); i++ {
+			a, b := path[i-1], path[i]
+			d := abs(a.X-b.X) + abs(a.Y-b.Y) + abs(a.Z-b.Z)
+			if d != 1 || !open(m, b) || visited[b] {
+				t.Fatalf("Expected the route to follow passages, but it steps from %+v to %+v", a, b)
+			}
+			if a.Z != b.Z {
+				if c, _ := m.Cell(a.X, a.Y, a.Z); b.Z > a.Z && c != maze.StairUp && c != maze.StairUpDown && a != m.Start() {
+					t.Fatalf("Expected stairs up at %+v, got %q", a, c)
+				}
+			}
+			visited[b] = true
+		}
+	}
+}
+
+func TestMaze3DCrossesLevels(t *testing.T) {
+	m, err := maze.NewMaze3D(9, 9, 3)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	start, end := maze.Point3{X: 1, Y: 1, Z: 0}, maze.Point3{X: 7, Y: 7, Z: 2}
+	if err := m.Generate(3, &start, &end, 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.Start() != start || m.End() != end {
+		t.Errorf("Expected Start %+v and End %+v, got %+v and %+v", start, end, m.Start(), m.End())
+	}
+	checkMaze3D(t, m)
+	path, found := m.Solve()
+	if !found {
+		t.Fatalf("Expected a route from Start to End")
+	}
+	levels := map[int]bool{}
+	for _, p := range path {
+		levels[p.Z] = true
+	}
+	if len(levels) != 3 {
+		t.Errorf("Expected the route to cross all 3 levels, got %v", levels)
+	}
+}
+
+func TestMaze3DRender(t *testing.T) {
+	m, err := maze.NewMaze3D(7, 5, 2)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	path, _ := m.Solve()
+	out := m.Render(path)
+	levels := strings.Split(out, "\n\n")
+	if len(levels) != 2 || !strings.HasPrefix(levels[0], "Level 0:\n") || !strings.HasPrefix(levels[1], "Level 1:\n") {
+		t.Fatalf("Expected two levels under headings, got:\n%s", out)
+	}
+	for _, level := range levels {
+		lines := strings.Split(strings.TrimSuffix(level, "\n"), "\n")
+		if len(lines) != 1+m.Height() {
+			t.Errorf("Expected %d rows per level, got %d", m.Height(), len(lines)-1)
+		}
+	}
+	if !strings.ContainsAny(out, string([]rune{rune(maze.StairUp), rune(maze.StairDown), rune(maze.StairUpDown)})) {
+		t.Errorf("Expected stairs between the levels, got:\n%s", out)
+	}
+	if strings.Contains(m.String(), string(maze.SolutionPath)) {
+		t.Errorf("Expected String to render the maze without the solution")
+	}
+}
+
+func TestMaze3DErrors(t *testing.T) {
+	if _, err := maze.NewMaze3D(9, 9, 0); err == nil {
+		t.Errorf("Expected an error for a maze without levels")
+	}
+	if _, err := maze.NewMaze3D(1, 9, 2); err == nil {
+		t.Errorf("Expected an error for a level without cells")
+	}
+	m, err := maze.NewMaze3D(9, 9, 2)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetGenerator(maze.Eller{}); err == nil || !strings.Contains(err.Error(), "3D") {
+		t.Errorf("Expected Eller's algorithm to be rejected for a 3D grid, got %v", err)
+	}
+	for _, p := range []maze.Point3{{X: 2, Y: 1, Z: 0}, {X: 1, Y: 1, Z: 2}, {X: 9, Y: 1, Z: 0}} {
+		if err := m.Generate(1, &p, nil, 0.5); err == nil {
+			t.Errorf("Expected an error for start point %+v", p)
+		}
+	}
+	same := maze.Point3{X: 3, Y: 3, Z: 1}
+	if err := m.Generate(1, &same, &same, 0.5); err == nil {
+		t.Errorf("Expected an error for the same start and end")
+	}
+}
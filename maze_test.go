@@ -39,10 +39,10 @@ func TestNew(t *testing.T) {
 			t.Errorf("Expected height to be adjusted to 21, got %d", m.Height())
 		}
 		if m.DenWidth() != 11 {
-			t.Errorf("Expected denWidth to be adjusted to 11, got %d", m.denWidth)
+			t.Errorf("Expected denWidth to be adjusted to 11, got %d", m.DenWidth())
 		}
 		if m.DenHeight() != 7 {
-			t.Errorf("Expected denHeight to be adjusted to 7, got %d", m.denHeight)
+			t.Errorf("Expected denHeight to be adjusted to 7, got %d", m.DenHeight())
 		}
 	})
 
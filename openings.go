@@ -0,0 +1,173 @@
+package maze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Opening is a gap in the outer wall of the maze that serves as its Start or
+// End, as in printed mazes.
+type Opening struct {
+	// Side is the border the opening is on: "top", "bottom", "left" or "right".
+	// An empty side lets the maze pick the side too.
+	Side string
+	// Offset is the odd X coordinate of an opening on the top or bottom
+	// border, or the odd Y coordinate of one on the left or right border.
+	// Zero places the opening where it makes the route through the maze longest.
+	Offset int
+}
+
+// ParseOpening parses an opening description: a side optionally followed by
+// a colon and an offset, for example "left" or "top:7", or "auto" to let the
+// maze pick the side.
+func ParseOpening(spec string) (Opening, error) {
+	side, offset, hasOffset := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+	var o Opening
+	if side != "auto" {
+		o.Side = side
+	}
+	if hasOffset {
+		n, err := strconv.Atoi(strings.TrimSpace(offset))
+		if err != nil {
+			return Opening{}, fmt.Errorf("invalid opening offset %q: not an integer", offset)
+		}
+		o.Offset = n
+	}
+	return o, o.check()
+}
+
+// check validates an opening independently of the maze size.
+func (o Opening) check() error {
+	if o.Side == "" {
+		if o.Offset != 0 {
+			return fmt.Errorf("an opening with an offset needs a side")
+		}
+		return nil
+	}
+	if !validDoorSide(o.Side) {
+		return fmt.Errorf("invalid opening side: %s. use 'top', 'bottom', 'left', or 'right'", o.Side)
+	}
+	if o.Offset < 0 || (o.Offset > 0 && o.Offset%2 == 0) {
+		return fmt.Errorf("opening offset must be odd, got %d", o.Offset)
+	}
+	return nil
+}
+
+// SetOpenings makes the maze start at an entrance and end at an exit in its
+// outer wall, instead of at interior cells. Either may be nil to keep the
+// interior Start or End. An opening cannot be combined with a Start or End
+// given to Generate or held by a den.
+func (m *Maze) SetOpenings(entrance, exit *Opening) error {
+	for _, o := range []*Opening{entrance, exit} {
+		if o == nil {
+			continue
+		}
+		if err := o.check(); err != nil {
+			return err
+		}
+		if o.Offset > 0 {
+			limit := m.width
+			if o.Side == "left" || o.Side == "right" {
+				limit = m.height
+			}
+			if o.Offset >= limit-1 {
+				return fmt.Errorf("opening offset %d is outside the %s border", o.Offset, o.Side)
+			}
+		}
+	}
+	if entrance != nil && exit != nil && *entrance == *exit && entrance.Offset > 0 {
+		return fmt.Errorf("entrance and exit cannot be the same")
+	}
+	m.entrance, m.exit = entrance, exit
+	return nil
+}
+
+// Entrance returns the maze's entrance, or nil if it starts inside.
+func (m *Maze) Entrance() *Opening {
+	return m.entrance
+}
+
+// Exit returns the maze's exit, or nil if it ends inside.
+func (m *Maze) Exit() *Opening {
+	return m.exit
+}
+
+// openingPoints returns the border points an opening may be placed at,
+// that is those in front of a maze cell that is not masked or part of a den.
+func (m *Maze) openingPoints(o Opening) []Point {
+	sides := []string{o.Side}
+	if o.Side == "" {
+		// Joined borders have no outside to open to.
+		sides = nil
+		for _, side := range []string{"top", "right", "bottom", "left"} {
+			if !m.wrapsSide(side) {
+				sides = append(sides, side)
+			}
+		}
+	}
+
+	var points []Point
+	for _, side := range sides {
+		var at func(i int) (border, inner Point)
+		n := m.width
+		switch side {
+		case "top":
+			at = func(i int) (Point, Point) { return Point{X: i, Y: 0}, Point{X: i, Y: 1} }
+		case "bottom":
+			at = func(i int) (Point, Point) { return Point{X: i, Y: m.height - 1}, Point{X: i, Y: m.height - 2} }
+		case "left":
+			at = func(i int) (Point, Point) { return Point{X: 0, Y: i}, Point{X: 1, Y: i} }
+			n = m.height
+		default:
+			at = func(i int) (Point, Point) { return Point{X: m.width - 1, Y: i}, Point{X: m.width - 2, Y: i} }
+			n = m.height
+		}
+		for i := 1; i < n-1; i += 2 {
+			if o.Offset > 0 && i != o.Offset {
+				continue
+			}
+			border, inner := at(i)
+			if m.grid[inner.Y][inner.X] == Wall || m.IsMasked(border) || m.IsMasked(inner) ||
+				m.IsInsideDen(inner) || m.IsAdjacentToDen(inner) {
+				continue
+			}
+			points = append(points, border)
+		}
+	}
+	return points
+}
+
+// placeOpening picks the point of an opening farthest along the maze paths
+// from the given points, other than exclude, and opens it.
+func (m *Maze) placeOpening(o Opening, exclude *Point, from ...Point) (Point, error) {
+	var candidates []Point
+	for _, p := range m.openingPoints(o) {
+		if exclude == nil || p != *exclude {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		if o.Offset > 0 {
+			return Point{}, fmt.Errorf("cannot open the %s border at %d: no maze path behind it", o.Side, o.Offset)
+		}
+		if o.Side == "" {
+			return Point{}, fmt.Errorf("no room for an opening in the outer wall")
+		}
+		return Point{}, fmt.Errorf("no room for an opening on the %s border", o.Side)
+	}
+
+	_, _, distances := m.findFarthestPoint(from...)
+
+	// Every opening is one step from the maze cell behind it, so the farthest
+	// opening is the one in front of the farthest cell.
+	best, bestDistance := candidates[0], -1
+	for _, p := range candidates {
+		inner := Point{X: min(max(p.X, 1), m.width-2), Y: min(max(p.Y, 1), m.height-2)}
+		if d, ok := distances[inner]; ok && d > bestDistance {
+			best, bestDistance = p, d
+		}
+	}
+	m.grid[best.Y][best.X] = Path
+	return best, nil
+}
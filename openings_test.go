@@ -0,0 +1,179 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestOpeningsOnSides(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		m, err := maze.New(41, 21, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetOpenings(&maze.Opening{Side: "left"}, &maze.Opening{Side: "right"}); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+
+		if m.Start().X != 0 || m.End().X != m.Width()-1 {
+			t.Errorf("Expected the Start on the left border and the End on the right one, got %+v and %+v", m.Start(), m.End())
+		}
+		if cell, _ := m.Cell(m.Start().X, m.Start().Y); cell != maze.Start {
+			t.Errorf("Expected the Start marker in the border, got %q", cell)
+		}
+		if cell, _ := m.Cell(m.End().X, m.End().Y); cell != maze.End {
+			t.Errorf("Expected the End marker in the border, got %q", cell)
+		}
+
+		path, found := m.Solve()
+		if !found {
+			t.Fatal("Expected the maze to be solvable")
+		}
+		if path[0] != m.Start() || path[len(path)-1] != m.End() {
+			t.Errorf("Expected the solution to run between the openings, got %+v to %+v", path[0], path[len(path)-1])
+		}
+
+		// The exit is the right border opening farthest from the entrance.
+		distances := pathDistances(m, m.Start())
+		for y := 1; y < m.Height()-1; y += 2 {
+			if distances[maze.Point{X: m.Width() - 2, Y: y}] > distances[m.End()]-1 {
+				t.Errorf("Seed %d: an exit at row %d would make a longer route than %+v", seed, y, m.End())
+			}
+		}
+	}
+}
+
+func TestOpeningsAtOffsets(t *testing.T) {
+	m, err := maze.New(41, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetOpenings(&maze.Opening{Side: "top", Offset: 5}, &maze.Opening{Side: "bottom", Offset: 35}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(2, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.Start() != (maze.Point{X: 5, Y: 0}) || m.End() != (maze.Point{X: 35, Y: 20}) {
+		t.Errorf("Expected openings at {5, 0} and {35, 20}, got %+v and %+v", m.Start(), m.End())
+	}
+	if _, found := m.Solve(); !found {
+		t.Error("Expected the maze to be solvable")
+	}
+}
+
+func TestAutomaticOpenings(t *testing.T) {
+	m, err := maze.New(31, 21, 7, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetOpenings(&maze.Opening{}, &maze.Opening{}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(3, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	checkPerfectMaze(t, m)
+	onBorder := func(p maze.Point) bool {
+		return p.X == 0 || p.Y == 0 || p.X == m.Width()-1 || p.Y == m.Height()-1
+	}
+	if !onBorder(m.Start()) || !onBorder(m.End()) || m.Start() == m.End() {
+		t.Errorf("Expected two openings in the outer wall, got %+v and %+v", m.Start(), m.End())
+	}
+
+	// Only an entrance: the End stays inside, as far from it as possible.
+	m, err = maze.New(31, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetOpenings(&maze.Opening{Side: "bottom"}, nil); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(4, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.Start().Y != m.Height()-1 || onBorder(m.End()) {
+		t.Errorf("Expected an entrance at the bottom and an interior End, got %+v and %+v", m.Start(), m.End())
+	}
+	distances := pathDistances(m, m.Start())
+	for p, d := range distances {
+		if d > distances[m.End()] {
+			t.Errorf("Expected the End to be the farthest point, but %+v is farther", p)
+			break
+		}
+	}
+}
+
+func TestOpeningWithEndDen(t *testing.T) {
+	m, err := maze.New(41, 21, 9, 5)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetDenRole(0, maze.EndDen, nil); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.SetOpenings(&maze.Opening{Side: "top"}, nil); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(5, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.Start().Y != 0 || !m.IsInsideDen(m.End()) {
+		t.Errorf("Expected an entrance at the top and the End in the den, got %+v and %+v", m.Start(), m.End())
+	}
+	checkDenRoute(t, m, 0)
+}
+
+func TestOpeningErrors(t *testing.T) {
+	for _, spec := range []string{"middle", "top:4", "left:x", "auto:3", "right:-1"} {
+		if _, err := maze.ParseOpening(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+	for spec, expected := range map[string]maze.Opening{"left": {Side: "left"}, "Top:7": {Side: "top", Offset: 7}, "auto": {}} {
+		o, err := maze.ParseOpening(spec)
+		if err != nil || o != expected {
+			t.Errorf("ParseOpening(%q) = %+v, %v; want %+v", spec, o, err, expected)
+		}
+	}
+
+	m, err := maze.New(21, 11, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetOpenings(&maze.Opening{Side: "left", Offset: 11}, nil); err == nil {
+		t.Error("Expected error for an offset outside the border, but got nil")
+	}
+	if err := m.SetOpenings(&maze.Opening{Side: "left", Offset: 3}, &maze.Opening{Side: "left", Offset: 3}); err == nil {
+		t.Error("Expected error for the same entrance and exit, but got nil")
+	}
+
+	if err := m.SetOpenings(&maze.Opening{Side: "left"}, nil); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	start := maze.Point{X: 1, Y: 1}
+	if err := m.Generate(1, &start, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error for a Start point next to an entrance, but got nil")
+	}
+
+	// The mask hides the cell behind the exit.
+	mask, err := maze.NewMask(m.Width(), m.Height())
+	if err != nil {
+		t.Fatalf("Failed to create mask: %v", err)
+	}
+	mask.SetBlocked(19, 5, true)
+	if err := m.SetMask(mask); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.SetOpenings(nil, &maze.Opening{Side: "right", Offset: 5}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error for an exit behind a masked cell, but got nil")
+	}
+}
@@ -0,0 +1,370 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// PlacementStrategy decides where Generate puts the Start and End when they
+// are not given as points, held by a den or opened in the outer wall.
+type PlacementStrategy int
+
+const (
+	// LongestPath puts the Start and End at the two ends of the longest path
+	// in the maze. It is the default.
+	LongestPath PlacementStrategy = iota
+	// OppositeCorners puts the Start near the top-left corner and the End
+	// near the bottom-right one, or a given point's counterpart near the
+	// corner opposite to it.
+	OppositeCorners
+	// RandomMinDistance picks the Start and End at random, at least
+	// Placement.MinDistance steps apart along the paths.
+	RandomMinDistance
+	// TargetDistance picks the End whose distance along the paths from the
+	// Start is closest to Placement.Distance steps.
+	TargetDistance
+	// QuadrantPair keeps the Start in Placement.StartQuadrant and the End in
+	// Placement.EndQuadrant, as far apart as possible.
+	QuadrantPair
+	// FarthestFromDoor puts the Start as far as possible from the doors of
+	// all dens, and the End as far as possible from the Start.
+	FarthestFromDoor
+	// FixedPlacement is only reported: both the Start and End were given.
+	FixedPlacement
+)
+
+// placementNames maps the names accepted by ParsePlacement to strategies.
+var placementNames = map[string]PlacementStrategy{
+	"longest":  LongestPath,
+	"corners":  OppositeCorners,
+	"random":   RandomMinDistance,
+	"target":   TargetDistance,
+	"quadrant": QuadrantPair,
+	"door":     FarthestFromDoor,
+}
+
+// String returns the name of a strategy as accepted by ParsePlacement.
+func (s PlacementStrategy) String() string {
+	if s == FixedPlacement {
+		return "fixed"
+	}
+	for name, strategy := range placementNames {
+		if strategy == s {
+			return name
+		}
+	}
+	return fmt.Sprintf("PlacementStrategy(%d)", int(s))
+}
+
+// Quadrant is a quarter of the maze.
+type Quadrant int
+
+const (
+	// AnyQuadrant does not constrain the point.
+	AnyQuadrant Quadrant = iota
+	// TopLeft is the top-left quarter of the maze.
+	TopLeft
+	// TopRight is the top-right quarter of the maze.
+	TopRight
+	// BottomLeft is the bottom-left quarter of the maze.
+	BottomLeft
+	// BottomRight is the bottom-right quarter of the maze.
+	BottomRight
+)
+
+// quadrantNames maps the spellings accepted by ParsePlacement to quadrants.
+var quadrantNames = map[string]Quadrant{"any": AnyQuadrant, "tl": TopLeft, "tr": TopRight, "bl": BottomLeft, "br": BottomRight}
+
+// Placement configures how Generate places the Start and End.
+type Placement struct {
+	Strategy PlacementStrategy
+	// MinDistance is the least distance, in steps, between the Start and End
+	// for RandomMinDistance.
+	MinDistance int
+	// Distance is the target distance, in steps, for TargetDistance.
+	Distance int
+	// StartQuadrant and EndQuadrant constrain the points for QuadrantPair.
+	StartQuadrant, EndQuadrant Quadrant
+}
+
+// PlacementResult reports how Generate placed the Start and End.
+type PlacementResult struct {
+	// Strategy is the strategy that placed at least one of the points,
+	// or FixedPlacement if both were given.
+	Strategy PlacementStrategy
+	// Distance is the length, in steps, of the shortest path from Start to End.
+	Distance int
+}
+
+// ParsePlacement returns the placement described by spec: a strategy name
+// (longest, corners, random, target, quadrant or door) optionally followed by
+// a colon and comma-separated options, for example "random:min=40",
+// "target:n=60" or "quadrant:start=tl,end=br".
+func ParsePlacement(spec string) (Placement, error) {
+	name, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	strategy, ok := placementNames[strings.ToLower(name)]
+	if !ok {
+		return Placement{}, fmt.Errorf("unknown placement strategy: %q. use longest, corners, random, target, quadrant or door", name)
+	}
+	opts, err := parseOptions(rest)
+	if err != nil {
+		return Placement{}, fmt.Errorf("invalid options for %s: %w", name, err)
+	}
+
+	p := Placement{Strategy: strategy}
+	switch strategy {
+	case RandomMinDistance:
+		p.MinDistance, err = opts.int("min", 0)
+	case TargetDistance:
+		p.Distance, err = opts.int("n", 0)
+	case QuadrantPair:
+		for key, q := range map[string]*Quadrant{"start": &p.StartQuadrant, "end": &p.EndQuadrant} {
+			if value, ok := opts[key]; ok {
+				delete(opts, key)
+				if *q, ok = quadrantNames[strings.ToLower(value)]; !ok {
+					err = fmt.Errorf("invalid quadrant: %q. use 'tl', 'tr', 'bl', 'br', or 'any'", value)
+				}
+			}
+		}
+	}
+	if err == nil {
+		err = opts.check()
+	}
+	if err == nil {
+		err = p.check()
+	}
+	if err != nil {
+		return Placement{}, fmt.Errorf("invalid options for %s: %w", name, err)
+	}
+	return p, nil
+}
+
+// check validates a placement.
+func (p Placement) check() error {
+	if p.Strategy < LongestPath || p.Strategy >= FixedPlacement {
+		return fmt.Errorf("invalid placement strategy: %d", p.Strategy)
+	}
+	if p.MinDistance < 0 || p.Distance < 0 {
+		return fmt.Errorf("placement distances must not be negative")
+	}
+	for _, q := range []Quadrant{p.StartQuadrant, p.EndQuadrant} {
+		if q < AnyQuadrant || q > BottomRight {
+			return fmt.Errorf("invalid quadrant: %d", q)
+		}
+	}
+	return nil
+}
+
+// SetPlacement sets the strategy Generate uses to place the Start and End.
+// The strategy places whichever of the two is not given otherwise; an
+// entrance or exit can only be combined with LongestPath.
+func (m *Maze) SetPlacement(p Placement) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	m.placement = p
+	return nil
+}
+
+// Placement returns the maze's placement settings.
+func (m *Maze) Placement() Placement {
+	return m.placement
+}
+
+// PlacementResult reports how the last Generate placed the Start and End.
+func (m *Maze) PlacementResult() PlacementResult {
+	return m.placed
+}
+
+// placeWithStrategy places the Start and End with the maze's placement
+// strategy. A point that is already fixed stays where it is, and the other
+// one is placed relative to it.
+func (m *Maze) placeWithStrategy(r *rand.Rand, generationStart Point, fixedStart, fixedEnd *Point) error {
+	p := m.placement
+	switch {
+	case fixedStart != nil && fixedEnd != nil:
+		m.start, m.end = *fixedStart, *fixedEnd
+		return nil
+	case fixedStart != nil:
+		m.start = *fixedStart
+		end, err := m.placeCounterpart(r, m.start, p.EndQuadrant)
+		m.end = end
+		return err
+	case fixedEnd != nil:
+		m.end = *fixedEnd
+		start, err := m.placeCounterpart(r, m.end, p.StartQuadrant)
+		m.start = start
+		return err
+	}
+
+	_, _, distances := m.findFarthestPoint(generationStart)
+	cells := m.reachedCells(distances)
+	if len(cells) < 2 {
+		return fmt.Errorf("not enough cells to place the start and end")
+	}
+	switch p.Strategy {
+	case OppositeCorners:
+		m.start = nearestTo(cells, Point{X: 0, Y: 0})
+	case RandomMinDistance:
+		// Start from a random cell, or from an end of the longest path if the
+		// random one has no cell far enough away.
+		m.start = cells[r.Intn(len(cells))]
+		if far, dist, _ := m.findFarthestPoint(m.start); dist < p.MinDistance {
+			m.start = far
+		}
+	case QuadrantPair:
+		// Walk the longest path within the quadrants twice, so that the
+		// Start does not depend much on where generation began.
+		start, ok := m.farthestIn(generationStart, p.StartQuadrant)
+		if !ok {
+			return fmt.Errorf("no cell for the start in its quadrant")
+		}
+		end, ok := m.farthestIn(start, p.EndQuadrant)
+		if !ok {
+			return fmt.Errorf("no cell for the end in its quadrant")
+		}
+		m.start, _ = m.farthestIn(end, p.StartQuadrant)
+	case FarthestFromDoor:
+		doors := m.Doors()
+		if len(doors) == 0 {
+			return fmt.Errorf("placement by den door needs a den with a door")
+		}
+		m.start, _, _ = m.findFarthestPoint(doors...)
+		m.end, _, _ = m.findFarthestPoint(m.start)
+		return nil
+	default:
+		m.start, _, _ = m.findFarthestPoint(generationStart)
+	}
+
+	end, err := m.placeCounterpart(r, m.start, p.EndQuadrant)
+	m.end = end
+	return err
+}
+
+// placeCounterpart places the automatic point for a fixed one with the maze's
+// placement strategy, inside the given quadrant for QuadrantPair.
+func (m *Maze) placeCounterpart(r *rand.Rand, fixed Point, quadrant Quadrant) (Point, error) {
+	p := m.placement
+	_, _, distances := m.findFarthestPoint(fixed)
+	cells := m.reachedCells(distances)
+	var candidates []Point
+	for _, c := range cells {
+		if c != fixed {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return Point{}, fmt.Errorf("no cell is reachable from %+v", fixed)
+	}
+
+	switch p.Strategy {
+	case OppositeCorners:
+		corner := Point{X: m.width - 1, Y: m.height - 1}
+		if 2*fixed.X > m.width {
+			corner.X = 0
+		}
+		if 2*fixed.Y > m.height {
+			corner.Y = 0
+		}
+		return nearestTo(candidates, corner), nil
+	case RandomMinDistance:
+		var far []Point
+		for _, c := range candidates {
+			if distances[c] >= p.MinDistance {
+				far = append(far, c)
+			}
+		}
+		if len(far) == 0 {
+			return Point{}, fmt.Errorf("no cell is at least %d steps from %+v", p.MinDistance, fixed)
+		}
+		return far[r.Intn(len(far))], nil
+	case TargetDistance:
+		best := candidates[0]
+		for _, c := range candidates {
+			if abs(distances[c]-p.Distance) < abs(distances[best]-p.Distance) {
+				best = c
+			}
+		}
+		return best, nil
+	case QuadrantPair:
+		c, ok := m.farthestIn(fixed, quadrant)
+		if !ok {
+			return Point{}, fmt.Errorf("no cell is reachable in the quadrant")
+		}
+		return c, nil
+	case FarthestFromDoor:
+		doors := m.Doors()
+		if len(doors) == 0 {
+			return Point{}, fmt.Errorf("placement by den door needs a den with a door")
+		}
+		if c, _, _ := m.findFarthestPoint(doors...); c != fixed {
+			return c, nil
+		}
+		c, _, _ := m.findFarthestPoint(fixed)
+		return c, nil
+	default:
+		c, _, _ := m.findFarthestPoint(fixed)
+		return c, nil
+	}
+}
+
+// reachedCells returns, in row-major order, the maze cells outside the dens
+// that a search from findFarthestPoint reached.
+func (m *Maze) reachedCells(distances map[Point]int) []Point {
+	var cells []Point
+	for _, c := range m.generationCells() {
+		if _, ok := distances[c]; ok {
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+// farthestIn returns the cell in a quadrant farthest along the paths from a point.
+func (m *Maze) farthestIn(from Point, q Quadrant) (Point, bool) {
+	_, _, distances := m.findFarthestPoint(from)
+	cells := m.reachedCells(distances)
+	best, found := Point{}, false
+	for _, c := range cells {
+		if c != from && m.inQuadrant(c, q) && (!found || distances[c] > distances[best]) {
+			best, found = c, true
+		}
+	}
+	return best, found
+}
+
+// inQuadrant reports whether a point lies in a quadrant of the maze.
+func (m *Maze) inQuadrant(p Point, q Quadrant) bool {
+	left, top := 2*p.X < m.width, 2*p.Y < m.height
+	switch q {
+	case TopLeft:
+		return left && top
+	case TopRight:
+		return !left && top
+	case BottomLeft:
+		return left && !top
+	case BottomRight:
+		return !left && !top
+	}
+	return true
+}
+
+// nearestTo returns the point closest to a target, the first one on a tie.
+func nearestTo(points []Point, target Point) Point {
+	best := points[0]
+	for _, p := range points {
+		if abs(p.X-target.X)+abs(p.Y-target.Y) < abs(best.X-target.X)+abs(best.Y-target.Y) {
+			best = p
+		}
+	}
+	return best
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
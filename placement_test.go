@@ -0,0 +1,191 @@
+package maze_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// generatePlaced generates a maze with the given placement and den size.
+func generatePlaced(t *testing.T, seed int64, p maze.Placement, denWidth, denHeight int) *maze.Maze {
+	t.Helper()
+	m, err := maze.New(41, 31, denWidth, denHeight)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetPlacement(p); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	return m
+}
+
+// checkPlacementResult verifies that the reported distance is the length of the solution.
+func checkPlacementResult(t *testing.T, m *maze.Maze, strategy maze.PlacementStrategy) {
+	t.Helper()
+	result := m.PlacementResult()
+	if result.Strategy != strategy {
+		t.Errorf("Expected strategy %v to be reported, got %v", strategy, result.Strategy)
+	}
+	path, found := m.Solve()
+	if !found {
+		t.Fatal("Expected the maze to be solvable")
+	}
+	if result.Distance != len(path)-1 {
+		t.Errorf("Expected a reported distance of %d, got %d", len(path)-1, result.Distance)
+	}
+}
+
+func TestLongestPathPlacement(t *testing.T) {
+	m := generatePlaced(t, 1, maze.Placement{}, 0, 0)
+	checkPlacementResult(t, m, maze.LongestPath)
+
+	start, end := maze.Point{X: 1, Y: 1}, maze.Point{X: 39, Y: 29}
+	if err := m.Generate(1, &start, &end, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	checkPlacementResult(t, m, maze.FixedPlacement)
+}
+
+func TestOppositeCornersPlacement(t *testing.T) {
+	m := generatePlaced(t, 2, maze.Placement{Strategy: maze.OppositeCorners}, 0, 0)
+	checkPlacementResult(t, m, maze.OppositeCorners)
+	if m.Start() != (maze.Point{X: 1, Y: 1}) || m.End() != (maze.Point{X: 39, Y: 29}) {
+		t.Errorf("Expected the Start and End in opposite corners, got %+v and %+v", m.Start(), m.End())
+	}
+
+	// A given Start in the top-right corner gets its End in the bottom-left one.
+	start := maze.Point{X: 37, Y: 3}
+	if err := m.Generate(2, &start, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if m.End() != (maze.Point{X: 1, Y: 29}) {
+		t.Errorf("Expected the End in the bottom-left corner, got %+v", m.End())
+	}
+}
+
+func TestRandomMinDistancePlacement(t *testing.T) {
+	seen := make(map[maze.Point]bool)
+	for seed := int64(1); seed <= 10; seed++ {
+		m := generatePlaced(t, seed, maze.Placement{Strategy: maze.RandomMinDistance, MinDistance: 60}, 0, 0)
+		checkPlacementResult(t, m, maze.RandomMinDistance)
+		if d := m.PlacementResult().Distance; d < 60 {
+			t.Errorf("Seed %d: expected the Start and End at least 60 steps apart, got %d", seed, d)
+		}
+		seen[m.Start()] = true
+	}
+	if len(seen) < 5 {
+		t.Errorf("Expected random Start points, got only %d different ones", len(seen))
+	}
+
+	m, err := maze.New(11, 11, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetPlacement(maze.Placement{Strategy: maze.RandomMinDistance, MinDistance: 1000}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error when no cells are far enough apart, but got nil")
+	}
+}
+
+func TestTargetDistancePlacement(t *testing.T) {
+	for _, target := range []int{10, 50, 100} {
+		m := generatePlaced(t, 3, maze.Placement{Strategy: maze.TargetDistance, Distance: target}, 0, 0)
+		checkPlacementResult(t, m, maze.TargetDistance)
+		// Cells are two steps apart, so the closest distance is at most one step off.
+		if d := m.PlacementResult().Distance; abs(d-target) > 1 {
+			t.Errorf("Expected a distance close to %d, got %d", target, d)
+		}
+	}
+}
+
+func TestQuadrantPlacement(t *testing.T) {
+	p := maze.Placement{Strategy: maze.QuadrantPair, StartQuadrant: maze.BottomLeft, EndQuadrant: maze.TopLeft}
+	for seed := int64(1); seed <= 5; seed++ {
+		m := generatePlaced(t, seed, p, 0, 0)
+		checkPlacementResult(t, m, maze.QuadrantPair)
+		if m.Start().X > 20 || m.Start().Y < 15 {
+			t.Errorf("Expected the Start in the bottom-left quadrant, got %+v", m.Start())
+		}
+		if m.End().X > 20 || m.End().Y > 15 {
+			t.Errorf("Expected the End in the top-left quadrant, got %+v", m.End())
+		}
+	}
+}
+
+func TestFarthestFromDoorPlacement(t *testing.T) {
+	m := generatePlaced(t, 4, maze.Placement{Strategy: maze.FarthestFromDoor}, 9, 5)
+	checkPlacementResult(t, m, maze.FarthestFromDoor)
+	distances := pathDistances(m, m.Door())
+	for p, d := range distances {
+		if d > distances[m.Start()] && p.X%2 == 1 && p.Y%2 == 1 && !m.IsInsideDen(p) {
+			t.Errorf("Expected the Start farthest from the door, but %+v is farther", p)
+			break
+		}
+	}
+
+	m, err := maze.New(21, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetPlacement(maze.Placement{Strategy: maze.FarthestFromDoor}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error for a maze without a den, but got nil")
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected maze.Placement
+	}{
+		{"longest", maze.Placement{}},
+		{"Corners", maze.Placement{Strategy: maze.OppositeCorners}},
+		{"random:min=40", maze.Placement{Strategy: maze.RandomMinDistance, MinDistance: 40}},
+		{"target:n=60", maze.Placement{Strategy: maze.TargetDistance, Distance: 60}},
+		{"quadrant:start=tl,end=BR", maze.Placement{Strategy: maze.QuadrantPair, StartQuadrant: maze.TopLeft, EndQuadrant: maze.BottomRight}},
+		{"door", maze.Placement{Strategy: maze.FarthestFromDoor}},
+	}
+	for _, tc := range testCases {
+		p, err := maze.ParsePlacement(tc.spec)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, but got %v", tc.spec, err)
+		}
+		if p != tc.expected {
+			t.Errorf("ParsePlacement(%q) = %+v; want %+v", tc.spec, p, tc.expected)
+		}
+		if name := p.Strategy.String(); name != strings.ToLower(strings.SplitN(tc.spec, ":", 2)[0]) {
+			t.Errorf("Expected strategy name %q, got %q", tc.spec, name)
+		}
+	}
+
+	for _, spec := range []string{"middle", "random:min=x", "target:n=-3", "quadrant:start=center", "corners:n=3"} {
+		if _, err := maze.ParsePlacement(spec); err == nil {
+			t.Errorf("Expected error for %q, but got nil", spec)
+		}
+	}
+
+	m, err := maze.New(21, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetPlacement(maze.Placement{Strategy: maze.FixedPlacement}); err == nil {
+		t.Error("Expected error for a strategy that is only reported, but got nil")
+	}
+	if err := m.SetPlacement(maze.Placement{Strategy: maze.OppositeCorners}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.SetOpenings(&maze.Opening{Side: "left"}, nil); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Error("Expected error for a strategy combined with an entrance, but got nil")
+	}
+}
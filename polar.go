@@ -0,0 +1,215 @@
+package maze
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// PolarMaze is a round maze, also known as a theta maze, made of concentric
+// rings of cells around a single center cell. The rings are split into more
+// cells the farther out they are, so that cells stay about as wide as they are
+// deep. Cells are addressed as a Point by their position in the ring (X),
+// counted clockwise from the right, and by ring (Y), where ring 0 is the center.
+//
+// The den is the central hub: the innermost rings, with a single door. The
+// Start is in the center and the End on the outer rim, where the maze has its
+// exit, unless they are given elsewhere.
+type PolarMaze struct {
+	graphMaze
+	// first holds the graph cell of the first cell of each ring.
+	first []int
+	// counts holds the number of cells in each ring.
+	counts []int
+}
+
+// NewPolar creates a round maze with the given number of rings, counting the
+// center cell as ring 0. A hub above 0 makes the innermost hub rings the den.
+func NewPolar(rings, hub int) (*PolarMaze, error) {
+	if rings < 2 {
+		return nil, fmt.Errorf("a round maze needs at least 2 rings")
+	}
+	if hub < 0 {
+		return nil, fmt.Errorf("hub must be non-negative")
+	}
+	if hub >= rings {
+		return nil, fmt.Errorf("hub (%d rings) is too large for a maze of %d rings", hub, rings)
+	}
+
+	p := &PolarMaze{counts: []int{1}, first: []int{0}}
+	for r := 1; r < rings; r++ {
+		// Split each cell of the ring inside when the cells would otherwise be
+		// more than about one and a half times as wide as they are deep.
+		inner := p.counts[r-1]
+		ratio := max(int(math.Round(2*math.Pi*float64(r)/float64(inner))), 1)
+		p.first = append(p.first, p.first[r-1]+inner)
+		p.counts = append(p.counts, inner*ratio)
+	}
+	p.graph = newGraph("polar", p.first[rings-1]+p.counts[rings-1])
+	p.cellAt = func(pt Point) int {
+		if pt.Y < 0 || pt.Y >= rings || pt.X < 0 || pt.X >= p.counts[pt.Y] {
+			return -1
+		}
+		return p.first[pt.Y] + pt.X
+	}
+	p.pointOf = func(c int) Point {
+		r := rings - 1
+		for p.first[r] > c {
+			r--
+		}
+		return Point{X: c - p.first[r], Y: r}
+	}
+
+	// The sides of a cell are its clockwise and counterclockwise neighbors,
+	// the cell inside it and the cells outside it, in that order.
+	for c := range p.adj {
+		pt := p.pointOf(c)
+		r := pt.Y
+		var neighbors []int
+		if r == 0 {
+			neighbors = []int{-1, -1, -1}
+		} else {
+			n := p.counts[r]
+			inside := pt.X * p.counts[r-1] / n
+			neighbors = []int{p.cellAt(Point{X: (pt.X + 1) % n, Y: r}), p.cellAt(Point{X: (pt.X + n - 1) % n, Y: r}), p.cellAt(Point{X: inside, Y: r - 1})}
+		}
+		if r+1 < rings {
+			ratio := p.counts[r+1] / p.counts[r]
+			for j := 0; j < ratio; j++ {
+				neighbors = append(neighbors, p.cellAt(Point{X: pt.X*ratio + j, Y: r + 1}))
+			}
+		} else {
+			neighbors = append(neighbors, -1)
+		}
+		p.setNeighbors(c, neighbors...)
+		p.den[c] = r < hub
+	}
+	p.ahead = func(prev, cur int) int {
+		switch k := p.side(cur, prev); {
+		case p.first[1] > cur:
+			return -1 // The center cell has no single neighbor straight on.
+		case k == 0 || k == 1:
+			return p.adj[cur][1-k]
+		case k == 2:
+			return p.adj[cur][3]
+		default:
+			return p.adj[cur][2]
+		}
+	}
+	p.reset()
+	return p, nil
+}
+
+// Rings returns the number of rings, including the center cell.
+func (p *PolarMaze) Rings() int { return len(p.counts) }
+
+// RingSize returns the number of cells in ring r.
+func (p *PolarMaze) RingSize(r int) int {
+	if r < 0 || r >= len(p.counts) {
+		return 0
+	}
+	return p.counts[r]
+}
+
+// Generate carves a perfect maze with the maze's generator, opens the hub's
+// door and places the Start and End. A seed reproduces the maze. The Start
+// is the center cell unless start is given, and the End is the cell on the
+// outer rim farthest from the Start unless end is given. The bias is the
+// chance of carving straight on, for the generators that honour it.
+func (p *PolarMaze) Generate(seed int64, start, end *Point, bias float64) error {
+	s, err := p.cell(start, "start", true)
+	if err != nil {
+		return err
+	}
+	e, err := p.cell(end, "end", false)
+	if err != nil {
+		return err
+	}
+	if s < 0 {
+		s = 0
+	}
+	if err := p.generate(seed, p.Generator(), bias, s, e); err != nil {
+		return err
+	}
+	if e < 0 {
+		outer := len(p.counts) - 1
+		distances := p.distances(p.start)
+		p.end = p.first[outer]
+		for c := p.first[outer]; c < len(p.adj); c++ {
+			if distances[c] > distances[p.end] {
+				p.end = c
+			}
+		}
+	}
+	return nil
+}
+
+// Exit reports whether the End is on the outer rim, where the rim opens to
+// let the solver out.
+func (p *PolarMaze) Exit() bool {
+	return p.end >= p.first[len(p.counts)-1]
+}
+
+// WriteSVG draws the maze as an SVG image, with rings ringSize pixels deep,
+// and with the cells of solution joined by a line.
+func (p *PolarMaze) WriteSVG(w io.Writer, ringSize float64, solution []Point) error {
+	if ringSize <= 0 {
+		return fmt.Errorf("ring size must be positive")
+	}
+	rings := len(p.counts)
+	margin := ringSize / 2
+	size := 2*float64(rings)*ringSize + 2*margin
+	center := svgPoint{x: size / 2, y: size / 2}
+	// bounds returns the inner and outer radius and the first and last angle of a cell.
+	bounds := func(c int) (float64, float64, float64, float64) {
+		pt := p.pointOf(c)
+		turn := 2 * math.Pi / float64(p.counts[pt.Y])
+		return float64(pt.Y) * ringSize, float64(pt.Y+1) * ringSize, float64(pt.X) * turn, float64(pt.X+1) * turn
+	}
+	// middle returns the center of a cell.
+	middle := func(c int) svgPoint {
+		if c < p.first[1] {
+			return center
+		}
+		r0, r1, a0, a1 := bounds(c)
+		return polar(center, (r0+r1)/2, (a0+a1)/2)
+	}
+
+	s := &svgWriter{w: w}
+	s.begin(size, size)
+	for c := range p.adj {
+		if !p.den[c] {
+			continue
+		}
+		if c < p.first[1] {
+			s.marker(center, ringSize, svgDenColor)
+			continue
+		}
+		r0, r1, a0, a1 := bounds(c)
+		s.sector(center, r0, r1, a0, a1, svgDenColor)
+	}
+	for c := p.first[1]; c < len(p.adj); c++ {
+		r0, r1, a0, a1 := bounds(c)
+		if !p.linked[c][2] {
+			s.arcWall(center, r0, a0, a1)
+		}
+		if !p.linked[c][0] {
+			s.wall(polar(center, r0, a1), polar(center, r1, a1))
+		}
+		if p.adj[c][3] < 0 && !(c == p.end && p.Exit()) {
+			s.arcWall(center, r1, a0, a1)
+		}
+	}
+	path := make([]svgPoint, 0, len(solution))
+	for _, pt := range solution {
+		if c := p.cellAt(pt); c >= 0 {
+			path = append(path, middle(c))
+		}
+	}
+	s.solution(path)
+	if p.start >= 0 {
+		s.marker(middle(p.start), ringSize/3, svgStartColor)
+		s.marker(middle(p.end), ringSize/3, svgEndColor)
+	}
+	return s.end()
+}
@@ -0,0 +1,188 @@
+package maze_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// checkPolarMaze verifies that every cell of a round maze can be reached from
+// the Start, by a single route outside the hub, and the hub only through its door.
+func checkPolarMaze(t *testing.T, p *maze.PolarMaze) {
+	t.Helper()
+	seen := map[maze.Point]bool{p.Start(): true}
+	queue := []maze.Point{p.Start()}
+	passages, cells := 0, 0
+	for head := 0; head < len(queue); head++ {
+		c := queue[head]
+		if !p.IsInsideDen(c) {
+			cells++
+		}
+		for _, n := range p.Neighbors(c) {
+			if !p.Linked(c, n) {
+				continue
+			}
+			if p.IsInsideDen(c) != p.IsInsideDen(n) {
+				if den, cell, _ := p.Door(); !(c == den && n == cell) && !(c == cell && n == den) {
+					t.Fatalf("Expected the hub to be entered through its door only, got a passage from %+v to %+v", c, n)
+				}
+			}
+			if !p.IsInsideDen(c) && !p.IsInsideDen(n) {
+				passages++
+			}
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	total := 0
+	for r := 0; r < p.Rings(); r++ {
+		total += p.RingSize(r)
+	}
+	if len(seen) != total {
+		t.Fatalf("Expected all %d cells to be reachable, got %d", total, len(seen))
+	}
+	if passages/2 != cells-1 {
+		t.Errorf("Expected %d passages outside the hub for a perfect maze, got %d", cells-1, passages/2)
+	}
+}
+
+func TestPolarMaze(t *testing.T) {
+	for _, algo := range []string{"dfs", "growingtree:random", "huntandkill", "kruskal", "prim", "wilson", "aldousbroder"} {
+		p, err := maze.NewPolar(8, 2)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		g, err := maze.ParseGenerator(algo)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", algo, err)
+		}
+		if err := p.SetGenerator(g); err != nil {
+			t.Fatalf("Expected %s to support polar grids, got %v", algo, err)
+		}
+		if err := p.Generate(5, nil, nil, 0.5); err != nil {
+			t.Fatalf("Expected no error with %s, but got %v", algo, err)
+		}
+		checkPolarMaze(t, p)
+		if p.Start() != (maze.Point{X: 0, Y: 0}) {
+			t.Errorf("Expected the Start in the center, got %+v", p.Start())
+		}
+		if p.End().Y != p.Rings()-1 || !p.Exit() {
+			t.Errorf("Expected the End on the outer rim, got %+v", p.End())
+		}
+		path, found := p.Solve()
+		if !found || path[0] != p.Start() || path[len(path)-1] != p.End() {
+			t.Fatalf("Expected a route from Start to End with %s, got %v", algo, path)
+		}
+		for i := 1; i < len(path); i++ {
+			if !p.Linked(path[i-1], path[i]) {
+				t.Fatalf("Expected the route to follow passages, but %+v and %+v are not linked", path[i-1], path[i])
+			}
+		}
+	}
+}
+
+func TestPolarMazeRings(t *testing.T) {
+	p, err := maze.NewPolar(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if p.RingSize(0) != 1 {
+		t.Errorf("Expected a single center cell, got %d", p.RingSize(0))
+	}
+	for r := 1; r < p.Rings(); r++ {
+		inner, outer := p.RingSize(r-1), p.RingSize(r)
+		if outer < inner || outer%inner != 0 {
+			t.Errorf("Expected ring %d to split the %d cells inside it, got %d cells", r, inner, outer)
+		}
+	}
+	if p.RingSize(p.Rings()-1) <= p.RingSize(1) {
+		t.Errorf("Expected outer rings to have more cells than inner ones")
+	}
+	// Every cell has a cell inside it, neighbors on both sides in its ring,
+	// and the cells it was split into outside it.
+	if n, want := len(p.Neighbors(maze.Point{X: 0, Y: 1})), 3+p.RingSize(2)/p.RingSize(1); n != want {
+		t.Errorf("Expected a cell of the first ring to have %d neighbors, got %d", want, n)
+	}
+	if n := len(p.Neighbors(maze.Point{X: 0, Y: 0})); n != p.RingSize(1) {
+		t.Errorf("Expected the center to border the whole first ring, got %d neighbors", n)
+	}
+}
+
+func TestPolarMazePoints(t *testing.T) {
+	p, err := maze.NewPolar(6, 1)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	start, end := maze.Point{X: 3, Y: 2}, maze.Point{X: 1, Y: 4}
+	if err := p.Generate(9, &start, &end, 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if p.Start() != start || p.End() != end || p.Exit() {
+		t.Errorf("Expected Start %+v and End %+v without an exit, got %+v and %+v", start, end, p.Start(), p.End())
+	}
+	checkPolarMaze(t, p)
+
+	center := maze.Point{X: 0, Y: 0}
+	if err := p.Generate(9, nil, &center, 0.5); err == nil {
+		t.Errorf("Expected an error for an end in the hub")
+	}
+	outside := maze.Point{X: 6, Y: 1}
+	if err := p.Generate(9, &outside, nil, 0.5); err == nil {
+		t.Errorf("Expected an error for a start point outside the grid")
+	}
+}
+
+func TestPolarMazeErrors(t *testing.T) {
+	if _, err := maze.NewPolar(1, 0); err == nil {
+		t.Errorf("Expected an error for a maze without rings around the center")
+	}
+	if _, err := maze.NewPolar(4, 4); err == nil {
+		t.Errorf("Expected an error for a hub that fills the maze")
+	}
+	p, err := maze.NewPolar(4, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := p.SetGenerator(maze.Sidewinder{}); err == nil || !strings.Contains(err.Error(), "polar") {
+		t.Errorf("Expected sidewinder to be rejected for a polar grid, got %v", err)
+	}
+}
+
+func TestPolarMazeSVG(t *testing.T) {
+	p, err := maze.NewPolar(5, 1)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := p.Generate(2, nil, nil, 0.5); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	path, _ := p.Solve()
+	var buf bytes.Buffer
+	if err := p.WriteSVG(&buf, 10, path); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	svg := buf.String()
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Errorf("Expected an SVG document, got:\n%s", svg)
+	}
+
+	// Every cell outside the center has an inner arc and a clockwise radial
+	// wall, and the outer ring an outer arc too, except for the exit. Each
+	// passage removes one of them, and with a hub of only the center cell,
+	// there is a passage for each cell outside the center.
+	cells := 0
+	for r := 1; r < p.Rings(); r++ {
+		cells += p.RingSize(r)
+	}
+	walls := 2*cells + p.RingSize(p.Rings()-1) - 1 - cells
+	if got := strings.Count(svg, "<line") + strings.Count(svg, "fill=\"none\" stroke=\"#000\""); got != walls {
+		t.Errorf("Expected %d walls, got %d", walls, got)
+	}
+	if !strings.Contains(svg, "<polyline") {
+		t.Errorf("Expected a solution line")
+	}
+}
@@ -0,0 +1,61 @@
+package maze
+
+import "math/rand"
+
+// Prim carves the maze with randomized Prim's algorithm.
+// The maze grows outward from the start point by repeatedly opening a random
+// wall on its frontier, which gives a radial texture with many short, spiky branches.
+// The maze bias is the probability of continuing in the direction of the last
+// carved passage when that is possible.
+type Prim struct{}
+
+// Carve implements Generator.
+func (p Prim) Carve(m *Maze, r *rand.Rand, start Point) error {
+	return m.carveCells(p, r, start)
+}
+
+// wrapSafe implements wrapCarver.
+func (Prim) wrapSafe() {}
+
+// carveGraph implements graphCarver.
+func (Prim) carveGraph(g cellGraph, r *rand.Rand, start int) error {
+	visited := make([]bool, g.size())
+	visited[start] = true
+	frontier := frontierEdges(g, nil, start, visited)
+
+	for len(frontier) > 0 {
+		var i int
+		if !g.weighted() {
+			i = r.Intn(len(frontier))
+		} else {
+			i = weightedIndex(r, len(frontier), func(i int) float64 {
+				return g.weight(frontier[i][0], frontier[i][1])
+			})
+		}
+		e := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		// Keep extending the last passage straight ahead while the bias allows it.
+		for !visited[e[1]] {
+			g.link(e[0], e[1])
+			visited[e[1]] = true
+			frontier = frontierEdges(g, frontier, e[1], visited)
+
+			straight := g.straight(e[0], e[1])
+			if straight < 0 || visited[straight] || r.Float64() >= g.biasAt(e[1]) {
+				break
+			}
+			e = [2]int{e[1], straight}
+		}
+	}
+	return nil
+}
+
+// frontierEdges appends the passages from a carved cell to each of its unvisited neighbors.
+func frontierEdges(g cellGraph, frontier [][2]int, c int, visited []bool) [][2]int {
+	for _, n := range unvisited(g, c, visited) {
+		frontier = append(frontier, [2]int{c, n})
+	}
+	return frontier
+}
@@ -0,0 +1,29 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestPrim(t *testing.T) {
+	for _, bias := range []float64{0, 0.5, 1} {
+		m, err := maze.New(41, 21, 11, 7)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.Prim{}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		start := maze.Point{X: 3, Y: 5}
+		if err := m.Generate(5, &start, nil, nil, "", bias); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+		if m.Start() != start {
+			t.Errorf("Expected start point %+v, got %+v", start, m.Start())
+		}
+	}
+
+	checkReproducible(t, maze.Prim{})
+}
@@ -0,0 +1,32 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestSidewinder(t *testing.T) {
+	for _, bias := range []float64{0, 0.5, 1} {
+		m, err := maze.New(41, 21, 11, 7)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.Sidewinder{}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		if err := m.Generate(6, nil, nil, nil, "", bias); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		checkPerfectMaze(t, m)
+
+		// The top row is always one long corridor.
+		for x := 1; x < m.Width()-1; x++ {
+			if cell, _ := m.Cell(x, 1); cell == maze.Wall {
+				t.Errorf("Expected the top row to be open at x=%d:\n%s", x, gridString(m))
+			}
+		}
+	}
+
+	checkReproducible(t, maze.Sidewinder{})
+}
@@ -32,7 +32,7 @@ func (m *Maze) Solve() ([]Point, bool) {
 
 		// Explore neighbors (Up, Down, Left, Right)
 		for _, dir := range []Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
-			next := Point{X: current.X + dir.X, Y: current.Y + dir.Y}
+			next := m.step(current, dir)
 
 			// Check if the neighbor is within bounds
 			if next.X < 0 || next.X >= m.width || next.Y < 0 || next.Y >= m.height {
@@ -41,7 +41,7 @@ func (m *Maze) Solve() ([]Point, bool) {
 
 			// Check if the neighbor is a walkable path and hasn't been visited
 			cell := m.grid[next.Y][next.X]
-			if cell != Wall && !visited[next.Y][next.X] {
+			if cell != Wall && !visited[next.Y][next.X] && !m.IsMasked(next) {
 				visited[next.Y][next.X] = true
 				parent[next] = current
 				queue = append(queue, next)
@@ -55,6 +55,37 @@ func TestSolve(t *testing.T) {
 		}
 	})
 
+	t.Run("Shortest of several routes", func(t *testing.T) {
+		// Two routes lead from S to E; the lower one is two steps shorter.
+		m := &Maze{
+			width:  7,
+			height: 5,
+			grid: [][]Cell{
+				{Wall, Wall, Wall, Wall, Wall, Wall, Wall},
+				{Wall, Path, Path, Path, Path, Path, Wall},
+				{Wall, Path, Wall, Wall, Wall, Path, Wall},
+				{Wall, Start, Path, Path, Path, End, Wall},
+				{Wall, Wall, Wall, Wall, Wall, Wall, Wall},
+			},
+			start: Point{X: 1, Y: 3},
+			end:   Point{X: 5, Y: 3},
+		}
+
+		path, found := m.Solve()
+		if !found {
+			t.Fatal("Expected to find a path, but did not")
+		}
+		expectedPath := []Point{{1, 3}, {2, 3}, {3, 3}, {4, 3}, {5, 3}}
+		if len(path) != len(expectedPath) {
+			t.Fatalf("Expected path length of %d, got %d", len(expectedPath), len(path))
+		}
+		for i, p := range expectedPath {
+			if path[i] != p {
+				t.Errorf("Path point %d is incorrect. Expected %+v, got %+v", i, p, path[i])
+			}
+		}
+	})
+
 	t.Run("Start equals End", func(t *testing.T) {
 		m := &Maze{
 			width:  3,
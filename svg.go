@@ -0,0 +1,105 @@
+package maze
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Colors of the SVG images of mazes.
+const (
+	svgWallColor     = "#000"
+	svgDenColor      = "#ddd"
+	svgSolutionColor = "#c00"
+	svgStartColor    = "#2a2"
+	svgEndColor      = "#22c"
+)
+
+// svgPoint is a point of an SVG image.
+type svgPoint struct {
+	x, y float64
+}
+
+// svgWriter writes the elements of an SVG image and keeps the first error.
+type svgWriter struct {
+	w   io.Writer
+	err error
+}
+
+// printf writes formatted output unless an earlier write failed.
+func (s *svgWriter) printf(format string, args ...any) {
+	if s.err == nil {
+		_, s.err = fmt.Fprintf(s.w, format, args...)
+	}
+}
+
+// begin writes the header of an image of the given size with a white background.
+func (s *svgWriter) begin(width, height float64) {
+	s.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.1f\" height=\"%.1f\" viewBox=\"0 0 %.1f %.1f\">\n", width, height, width, height)
+	s.printf("<rect width=\"100%%\" height=\"100%%\" fill=\"#fff\"/>\n")
+}
+
+// end closes the image and returns the first error.
+func (s *svgWriter) end() error {
+	s.printf("</svg>\n")
+	return s.err
+}
+
+// points formats points for the points attribute of polygons and polylines.
+func (s *svgWriter) points(ps []svgPoint) string {
+	coords := make([]string, len(ps))
+	for i, p := range ps {
+		coords[i] = fmt.Sprintf("%.1f,%.1f", p.x, p.y)
+	}
+	return strings.Join(coords, " ")
+}
+
+// polygon writes a filled polygon without an outline.
+func (s *svgWriter) polygon(ps []svgPoint, fill string) {
+	s.printf("<polygon points=\"%s\" fill=\"%s\"/>\n", s.points(ps), fill)
+}
+
+// wall writes a wall from a to b.
+func (s *svgWriter) wall(a, b svgPoint) {
+	s.printf("<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\" stroke-width=\"2\" stroke-linecap=\"round\"/>\n", a.x, a.y, b.x, b.y, svgWallColor)
+}
+
+// solution writes the solution as a line through the given points.
+func (s *svgWriter) solution(ps []svgPoint) {
+	if len(ps) < 2 {
+		return
+	}
+	s.printf("<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\" stroke-linejoin=\"round\"/>\n", s.points(ps), svgSolutionColor)
+}
+
+// marker writes a dot, such as the one for the Start or the End.
+func (s *svgWriter) marker(p svgPoint, radius float64, fill string) {
+	s.printf("<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%.1f\" fill=\"%s\"/>\n", p.x, p.y, radius, fill)
+}
+
+// polar returns the point at the given radius and angle, in radians
+// clockwise from the positive x axis, around center.
+func polar(center svgPoint, radius, angle float64) svgPoint {
+	return svgPoint{x: center.x + radius*math.Cos(angle), y: center.y + radius*math.Sin(angle)}
+}
+
+// arcWall writes a wall along the circle of the given radius around center,
+// clockwise from angle a0 to angle a1, which must be less than half a turn apart.
+func (s *svgWriter) arcWall(center svgPoint, radius, a0, a1 float64) {
+	p0, p1 := polar(center, radius, a0), polar(center, radius, a1)
+	s.printf("<path d=\"M %.1f %.1f A %.1f %.1f 0 0 1 %.1f %.1f\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\" stroke-linecap=\"round\"/>\n", p0.x, p0.y, radius, radius, p1.x, p1.y, svgWallColor)
+}
+
+// sector writes a filled part of a ring around center, between the radii r0
+// and r1 and clockwise from angle a0 to angle a1.
+func (s *svgWriter) sector(center svgPoint, r0, r1, a0, a1 float64, fill string) {
+	large := 0
+	if a1-a0 > math.Pi {
+		large = 1
+	}
+	p0, p1 := polar(center, r1, a0), polar(center, r1, a1)
+	p2, p3 := polar(center, r0, a1), polar(center, r0, a0)
+	s.printf("<path d=\"M %.1f %.1f A %.1f %.1f 0 %d 1 %.1f %.1f L %.1f %.1f A %.1f %.1f 0 %d 0 %.1f %.1f Z\" fill=\"%s\"/>\n",
+		p0.x, p0.y, r1, r1, large, p1.x, p1.y, p2.x, p2.y, r0, r0, large, p3.x, p3.y, fill)
+}
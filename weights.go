@@ -0,0 +1,193 @@
+package maze
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DirectionWeights are the relative chances of carving a passage north, south,
+// east or west. Higher East and West weights give wide mazes with mostly
+// horizontal corridors, higher North and South weights tall ones. If all four
+// are zero, which is the default, every direction is equally likely.
+type DirectionWeights struct {
+	North, South, East, West float64
+	// Turn scales the chance of a move that leaves the current direction, for
+	// generators that carve by walking. Below 1 corridors run straighter, above
+	// 1 they twist more. Zero leaves turns alone, like 1.
+	Turn float64
+}
+
+// SetDirectionWeights sets the direction weights that every generator honours
+// when it chooses where to carve: the walking generators weigh the neighbor
+// they step to, Kruskal's and Prim's algorithms the passages they open first,
+// the binary tree and sidewinder the direction they link in, Eller's
+// algorithm how often it joins cells sideways or downwards, and recursive
+// division the orientation of its walls, where horizontal walls make
+// horizontal corridors.
+func (m *Maze) SetDirectionWeights(w DirectionWeights) error {
+	if err := w.check(); err != nil {
+		return err
+	}
+	m.weights = w
+	return nil
+}
+
+// DirectionWeights returns the maze's direction weights.
+func (m *Maze) DirectionWeights() DirectionWeights {
+	return m.weights
+}
+
+// check validates the weights.
+func (w DirectionWeights) check() error {
+	for _, v := range []float64{w.North, w.South, w.East, w.West, w.Turn} {
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("direction weights must be non-negative numbers")
+		}
+	}
+	return nil
+}
+
+// normalized returns the weights with the zero defaults replaced by neutral values.
+func (w DirectionWeights) normalized() DirectionWeights {
+	if w.North == 0 && w.South == 0 && w.East == 0 && w.West == 0 {
+		w.North, w.South, w.East, w.West = 1, 1, 1, 1
+	}
+	if w.Turn == 0 {
+		w.Turn = 1
+	}
+	return w
+}
+
+// neutral reports whether the weights favor nothing. Generators then draw
+// from the random source exactly as they did before weights existed, so that
+// seeds keep producing the same mazes.
+func (w DirectionWeights) neutral() bool {
+	n := w.normalized()
+	return n.North == n.South && n.South == n.East && n.East == n.West && n.Turn == 1
+}
+
+// weight returns the weight of a move in direction dir after a move in
+// direction last, which is the zero Point if there was none.
+func (w DirectionWeights) weight(dir, last Point) float64 {
+	w = w.normalized()
+	var d float64
+	switch {
+	case dir.Y < 0:
+		d = w.North
+	case dir.Y > 0:
+		d = w.South
+	case dir.X < 0:
+		d = w.West
+	default:
+		d = w.East
+	}
+	if last != (Point{}) && dir != last {
+		d *= w.Turn
+	}
+	return d
+}
+
+// horizontal and vertical return the average weights of the two directions
+// along each axis, for passages that have no direction of their own.
+func (w DirectionWeights) horizontal() float64 {
+	w = w.normalized()
+	return (w.East + w.West) / 2
+}
+
+func (w DirectionWeights) vertical() float64 {
+	w = w.normalized()
+	return (w.North + w.South) / 2
+}
+
+// axis returns the weight of an undirected passage along dir.
+func (w DirectionWeights) axis(dir Point) float64 {
+	if dir.X != 0 {
+		return w.horizontal()
+	}
+	return w.vertical()
+}
+
+// pick chooses one of the neighbors of from, weighing each by the maze's
+// direction weights for the move after a move in direction last.
+func (m *Maze) pick(r *rand.Rand, from, last Point, neighbors []Point) Point {
+	w := m.weights
+	if w.neutral() {
+		return neighbors[r.Intn(len(neighbors))]
+	}
+	return neighbors[weightedIndex(r, len(neighbors), func(i int) float64 {
+		return w.weight(m.direction(from, neighbors[i]), last)
+	})]
+}
+
+// odds scales the chance p of an event by the ratio of the weights for and
+// against it, so that equal weights keep p.
+func odds(p, favor, against float64) float64 {
+	if favor == against {
+		return p
+	}
+	if total := p*favor + (1-p)*against; total > 0 {
+		return p * favor / total
+	}
+	return p
+}
+
+// coin reports true with a chance of one half scaled by odds. Equal weights
+// flip a coin with r.Intn like the generators did before weights existed.
+func coin(r *rand.Rand, favor, against float64) bool {
+	if favor == against {
+		return r.Intn(2) == 0
+	}
+	return r.Float64() < odds(0.5, favor, against)
+}
+
+// weightedIndex picks an index below n with a chance proportional to its weight,
+// or uniformly if all weights are zero.
+func weightedIndex(r *rand.Rand, n int, weight func(i int) float64) int {
+	weights := make([]float64, n)
+	total := 0.0
+	for i := range weights {
+		weights[i] = weight(i)
+		total += weights[i]
+	}
+	if total == 0 {
+		return r.Intn(n)
+	}
+	roll := r.Float64() * total
+	for i, wt := range weights {
+		if roll < wt {
+			return i
+		}
+		roll -= wt
+	}
+	return n - 1
+}
+
+// shuffleEdges puts edges in random order. With a weight, the edges that
+// weigh more, such as passages along the favored axis, tend to come first, so
+// that algorithms that open passages in this order prefer them. A nil weight
+// makes every order equally likely.
+func shuffleEdges[E any](r *rand.Rand, edges []E, weight func(e E) float64) {
+	if weight == nil {
+		r.Shuffle(len(edges), func(i, j int) { edges[i], edges[j] = edges[j], edges[i] })
+		return
+	}
+	// Weighted random order: sort by u^(1/weight) for uniform u, largest first.
+	keys := make([]float64, len(edges))
+	order := make([]int, len(edges))
+	for i, e := range edges {
+		if wt := weight(e); wt > 0 {
+			keys[i] = math.Pow(r.Float64(), 1/wt)
+		} else {
+			keys[i] = -r.Float64()
+		}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return keys[order[i]] > keys[order[j]] })
+	sorted := make([]E, len(edges))
+	for i, o := range order {
+		sorted[i] = edges[o]
+	}
+	copy(edges, sorted)
+}
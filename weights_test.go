@@ -0,0 +1,128 @@
+package maze_test
+
+import (
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// countPassages returns the number of open walls between horizontally and
+// vertically neighboring cells.
+func countPassages(m *maze.Maze) (horizontal, vertical int) {
+	for y := 1; y < m.Height()-1; y++ {
+		for x := 1; x < m.Width()-1; x++ {
+			if cell, _ := m.Cell(x, y); cell == maze.Wall || m.IsInsideDen(maze.Point{X: x, Y: y}) {
+				continue
+			}
+			switch {
+			case x%2 == 0 && y%2 == 1:
+				horizontal++
+			case x%2 == 1 && y%2 == 0:
+				vertical++
+			}
+		}
+	}
+	return horizontal, vertical
+}
+
+func TestDirectionWeights(t *testing.T) {
+	wide := maze.DirectionWeights{North: 1, South: 1, East: 4, West: 4}
+	tall := maze.DirectionWeights{North: 4, South: 4, East: 1, West: 1}
+	for _, name := range maze.GeneratorNames() {
+		if name == "cave" {
+			continue // Caves are not perfect mazes and ignore the weights.
+		}
+		t.Run(name, func(t *testing.T) {
+			g, err := maze.ParseGenerator(name)
+			if err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			share := func(w maze.DirectionWeights) float64 {
+				h, v := 0, 0
+				for seed := int64(1); seed <= 3; seed++ {
+					m, err := maze.New(41, 41, 9, 5)
+					if err != nil {
+						t.Fatalf("Failed to create maze: %v", err)
+					}
+					if err := m.SetGenerator(g); err != nil {
+						t.Fatalf("Failed to set generator: %v", err)
+					}
+					if err := m.SetDirectionWeights(w); err != nil {
+						t.Fatalf("Expected no error, but got %v", err)
+					}
+					if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+						t.Fatalf("Expected no error, but got %v", err)
+					}
+					checkPerfectMaze(t, m)
+					mh, mv := countPassages(m)
+					h, v = h+mh, v+mv
+				}
+				return float64(h) / float64(h+v)
+			}
+			if wideShare, tallShare := share(wide), share(tall); wideShare <= tallShare {
+				t.Errorf("Expected more horizontal passages with wide weights, got a share of %.2f against %.2f", wideShare, tallShare)
+			}
+		})
+	}
+}
+
+func TestNeutralDirectionWeights(t *testing.T) {
+	for _, name := range maze.GeneratorNames() {
+		g, err := maze.ParseGenerator(name)
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		var grids []string
+		for _, w := range []maze.DirectionWeights{{}, {North: 2, South: 2, East: 2, West: 2, Turn: 1}} {
+			m, err := maze.New(31, 21, 7, 5)
+			if err != nil {
+				t.Fatalf("Failed to create maze: %v", err)
+			}
+			if err := m.SetGenerator(g); err != nil {
+				t.Fatalf("Failed to set generator: %v", err)
+			}
+			if err := m.SetDirectionWeights(w); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if err := m.Generate(5, nil, nil, nil, "", 0.5); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			grids = append(grids, gridString(m))
+		}
+		if grids[0] != grids[1] {
+			t.Errorf("%s: expected equal weights to give the same maze as no weights", name)
+		}
+	}
+
+	m, err := maze.New(21, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetDirectionWeights(maze.DirectionWeights{North: -1}); err == nil {
+		t.Error("Expected error for a negative weight, but got nil")
+	}
+}
+
+func TestTurnWeight(t *testing.T) {
+	turns := func(turn float64) int {
+		total := 0
+		for seed := int64(1); seed <= 3; seed++ {
+			m, err := maze.New(41, 41, 0, 0)
+			if err != nil {
+				t.Fatalf("Failed to create maze: %v", err)
+			}
+			if err := m.SetDirectionWeights(maze.DirectionWeights{Turn: turn}); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if err := m.Generate(seed, nil, nil, nil, "", 0); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			checkPerfectMaze(t, m)
+			total += m.Metrics().Turns * 1000 / m.Metrics().SolutionLength
+		}
+		return total
+	}
+	if straight, twisty := turns(0.2), turns(5); straight >= twisty {
+		t.Errorf("Expected fewer turns per step with a low turn weight, got %d against %d", straight, twisty)
+	}
+}
@@ -0,0 +1,79 @@
+package maze
+
+import "math/rand"
+
+// Wilson carves the maze with Wilson's algorithm.
+// Starting from a tree that holds only the start point, it repeatedly performs a
+// loop-erased random walk from a cell outside the tree until the walk hits the tree,
+// and then adds the walk's path to it. Every perfect maze of the given size is
+// equally likely, so the maze bias is ignored.
+type Wilson struct{}
+
+// Carve implements Generator.
+func (w Wilson) Carve(m *Maze, r *rand.Rand, start Point) error {
+	return m.carveCells(w, r, start)
+}
+
+// wrapSafe implements wrapCarver.
+func (Wilson) wrapSafe() {}
+
+// carveGraph implements graphCarver.
+func (Wilson) carveGraph(g cellGraph, r *rand.Rand, start int) error {
+	cells := connectedCells(g, start)
+	inTree := make([]bool, g.size())
+	inTree[start] = true
+
+	// next records the latest exit taken from each cell of the current walk.
+	// Overwriting it when the walk revisits a cell is what erases loops.
+	next := make([]int, g.size())
+	for _, i := range r.Perm(len(cells)) {
+		walkStart := cells[i]
+
+		// Walk randomly until the tree is reached.
+		previous := -1
+		for c := walkStart; !inTree[c]; previous, c = c, next[c] {
+			next[c] = g.pick(r, previous, c, g.neighbors(c))
+		}
+
+		// Carve the loop-erased walk into the tree.
+		for c := walkStart; !inTree[c]; c = next[c] {
+			inTree[c] = true
+			g.link(c, next[c])
+		}
+	}
+	return nil
+}
+
+// AldousBroder carves the maze with the Aldous-Broder algorithm.
+// It performs a single random walk from the start point and opens a passage
+// every time the walk enters a cell for the first time. Like Wilson, it yields
+// uniformly distributed perfect mazes and ignores the maze bias, but it is
+// slower, mostly near the end when few unvisited cells remain.
+type AldousBroder struct{}
+
+// Carve implements Generator.
+func (a AldousBroder) Carve(m *Maze, r *rand.Rand, start Point) error {
+	return m.carveCells(a, r, start)
+}
+
+// wrapSafe implements wrapCarver.
+func (AldousBroder) wrapSafe() {}
+
+// carveGraph implements graphCarver.
+func (AldousBroder) carveGraph(g cellGraph, r *rand.Rand, start int) error {
+	remaining := len(connectedCells(g, start)) - 1
+	visited := make([]bool, g.size())
+	visited[start] = true
+
+	previous := -1
+	for c := start; remaining > 0; {
+		next := g.pick(r, previous, c, g.neighbors(c))
+		if !visited[next] {
+			g.link(c, next)
+			visited[next] = true
+			remaining--
+		}
+		previous, c = c, next
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package maze_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+func TestUniformGenerators(t *testing.T) {
+	for _, g := range []maze.Generator{maze.Wilson{}, maze.AldousBroder{}} {
+		t.Run(fmt.Sprintf("%T", g), func(t *testing.T) {
+			for _, seed := range []int64{1, 2, 3} {
+				m, err := maze.New(41, 21, 11, 7)
+				if err != nil {
+					t.Fatalf("Failed to create maze: %v", err)
+				}
+				if err := m.SetGenerator(g); err != nil {
+					t.Fatalf("Failed to set generator: %v", err)
+				}
+				if err := m.Generate(seed, nil, nil, nil, "", 0.5); err != nil {
+					t.Fatalf("Expected no error, but got %v", err)
+				}
+				checkPerfectMaze(t, m)
+			}
+
+			checkReproducible(t, g)
+		})
+	}
+}
+
+// TestWilsonUniformity checks that Wilson's algorithm picks each of the four
+// spanning trees of a 2x2 grid of cells about equally often.
+func TestWilsonUniformity(t *testing.T) {
+	const runs = 4000
+	counts := make(map[string]int)
+	for seed := int64(0); seed < runs; seed++ {
+		m, err := maze.New(5, 5, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to create maze: %v", err)
+		}
+		if err := m.SetGenerator(maze.Wilson{}); err != nil {
+			t.Fatalf("Failed to set generator: %v", err)
+		}
+		start := maze.Point{X: 1, Y: 1}
+		end := maze.Point{X: 3, Y: 3}
+		if err := m.Generate(seed, &start, &end, nil, "", 0); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		counts[gridString(m)]++
+	}
+	if len(counts) != 4 {
+		t.Fatalf("Expected 4 distinct mazes, got %d", len(counts))
+	}
+	for grid, n := range counts {
+		if n < runs/4*8/10 || n > runs/4*12/10 {
+			t.Errorf("Expected about %d occurrences of each maze, got %d for\n%s", runs/4, n, grid)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package maze
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wrap selects the pairs of opposite borders of a Maze that are joined, so
+// that passages can leave the maze on one side and come back on the other,
+// as in arcade games.
+type Wrap int
+
+const (
+	// NoWrap keeps the outer wall all around the maze. It is the default.
+	NoWrap Wrap = 0
+	// WrapHorizontal joins the left and right borders, which rolls the maze
+	// into a cylinder.
+	WrapHorizontal Wrap = 1
+	// WrapVertical joins the top and bottom borders.
+	WrapVertical Wrap = 2
+	// Torus joins both pairs of borders.
+	Torus = WrapHorizontal | WrapVertical
+)
+
+// wrapCarver is implemented by the generators that can carve a maze with
+// joined borders: those that grow from cell to cell and so follow passages
+// across a joined border. The others sweep the rows and columns from one
+// edge of the maze to the other.
+type wrapCarver interface {
+	wrapSafe()
+}
+
+// wrapGeneratorNames returns the sorted names of the built-in generators that
+// can carve a maze with joined borders.
+func wrapGeneratorNames() []string {
+	var names []string
+	for _, name := range GeneratorNames() {
+		if g, err := generators[name](options{}); err == nil {
+			if _, ok := g.(wrapCarver); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// wrapNames maps the names accepted by ParseWrap to wraps.
+var wrapNames = map[string]Wrap{
+	"none":       NoWrap,
+	"horizontal": WrapHorizontal,
+	"cylinder":   WrapHorizontal,
+	"vertical":   WrapVertical,
+	"torus":      Torus,
+	"both":       Torus,
+}
+
+// ParseWrap parses the name of a wrap: none, horizontal (or cylinder),
+// vertical, or torus (or both).
+func ParseWrap(name string) (Wrap, error) {
+	w, ok := wrapNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return NoWrap, fmt.Errorf("invalid wrap: %q. use 'none', 'horizontal', 'vertical' or 'torus'", name)
+	}
+	return w, nil
+}
+
+// SetWrap joins the borders of the maze that w selects. Generate then carves
+// passages across them, and Solve and the Start and End placement follow
+// those passages around. A joined border stays a wall line in the grid, but
+// every passage across it is open on both sides of the maze. The borders of
+// a wrapped direction must be at least 3 cells apart, and only the
+// generators that grow from cell to cell (see GeneratorNames) can carve the
+// maze.
+func (m *Maze) SetWrap(w Wrap) error {
+	if w < NoWrap || w > Torus {
+		return fmt.Errorf("invalid wrap: %d", w)
+	}
+	if w&WrapHorizontal != 0 && m.width < 7 {
+		return fmt.Errorf("maze width (%d) is too small to wrap around", m.width)
+	}
+	if w&WrapVertical != 0 && m.height < 7 {
+		return fmt.Errorf("maze height (%d) is too small to wrap around", m.height)
+	}
+	m.wrap = w
+	return nil
+}
+
+// Wrap returns the borders of the maze that are joined.
+func (m *Maze) Wrap() Wrap {
+	return m.wrap
+}
+
+// wrapsSide reports whether the outer wall on a side is joined to the opposite one.
+func (m *Maze) wrapsSide(side string) bool {
+	if side == "left" || side == "right" {
+		return m.wrap&WrapHorizontal != 0
+	}
+	return m.wrap&WrapVertical != 0
+}
+
+// wrapPoint brings a point that has left the maze across a joined border
+// back in from the opposite side. The two border lines are the same wall,
+// so they are a single step apart: one step past the right border is the
+// first cell column, and one step before the left border is the last one.
+func (m *Maze) wrapPoint(p Point) Point {
+	if m.wrap&WrapHorizontal != 0 {
+		if p.X >= m.width {
+			p.X -= m.width - 1
+		} else if p.X < 0 {
+			p.X += m.width - 1
+		}
+	}
+	if m.wrap&WrapVertical != 0 {
+		if p.Y >= m.height {
+			p.Y -= m.height - 1
+		} else if p.Y < 0 {
+			p.Y += m.height - 1
+		}
+	}
+	return p
+}
+
+// step returns the point one step from p in direction dir, across a joined border if need be.
+func (m *Maze) step(p, dir Point) Point {
+	return m.wrapPoint(Point{X: p.X + dir.X, Y: p.Y + dir.Y})
+}
+
+// direction returns the move from one point to a neighboring one, which is
+// a step of one between grid points or of two between cells. Neighbors on
+// opposite sides of a joined border are a move across it apart.
+func (m *Maze) direction(from, to Point) Point {
+	d := Point{X: to.X - from.X, Y: to.Y - from.Y}
+	if d.X > 2 {
+		d.X -= m.width - 1
+	} else if d.X < -2 {
+		d.X += m.width - 1
+	}
+	if d.Y > 2 {
+		d.Y -= m.height - 1
+	} else if d.Y < -2 {
+		d.Y += m.height - 1
+	}
+	return d
+}
+
+// inside reports whether a point lies within the outer wall, where a joined
+// border counts as inside since passages can cross it.
+func (m *Maze) inside(p Point) bool {
+	minX, minY := 1, 1
+	if m.wrap&WrapHorizontal != 0 {
+		minX = 0
+	}
+	if m.wrap&WrapVertical != 0 {
+		minY = 0
+	}
+	return p.X >= minX && p.X < m.width-minX && p.Y >= minY && p.Y < m.height-minY
+}
+
+// openWall opens a wall point, along with its twin on the opposite side if
+// it is on a joined border.
+func (m *Maze) openWall(p Point) {
+	m.grid[p.Y][p.X] = Path
+	if m.wrap&WrapHorizontal != 0 && (p.X == 0 || p.X == m.width-1) {
+		m.grid[p.Y][m.width-1-p.X] = Path
+	}
+	if m.wrap&WrapVertical != 0 && (p.Y == 0 || p.Y == m.height-1) {
+		m.grid[m.height-1-p.Y][p.X] = Path
+	}
+}
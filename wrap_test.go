@@ -0,0 +1,168 @@
+package maze_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vinser/maze"
+)
+
+// checkWrappedMaze verifies that a maze with joined borders is a perfect
+// maze, counting a passage across a joined border once, that both sides of
+// such a passage are open, and that the borders that are not joined are walls.
+func checkWrappedMaze(t *testing.T, m *maze.Maze) (crossings int) {
+	t.Helper()
+	w, h := m.Width(), m.Height()
+	horizontal, vertical := m.Wrap()&maze.WrapHorizontal != 0, m.Wrap()&maze.WrapVertical != 0
+	isOpen := func(x, y int) bool {
+		c, _ := m.Cell(x, y)
+		return c != maze.Wall
+	}
+	for y := 0; y < h; y++ {
+		if isOpen(0, y) != isOpen(w-1, y) || (!horizontal || y%2 == 0) && isOpen(0, y) {
+			t.Fatalf("Expected the left and right borders of row %d to match the wrap", y)
+		}
+	}
+	for x := 0; x < w; x++ {
+		if isOpen(x, 0) != isOpen(x, h-1) || (!vertical || x%2 == 0) && isOpen(x, 0) {
+			t.Fatalf("Expected the top and bottom borders of column %d to match the wrap", x)
+		}
+	}
+
+	cells, passages := 0, 0
+	for y := 1; y < h; y++ {
+		for x := 1; x < w; x++ {
+			switch {
+			case x%2 == 1 && y%2 == 1:
+				if !isOpen(x, y) {
+					t.Fatalf("Expected cell %d,%d to be carved", x, y)
+				}
+				cells++
+			case (x%2 == 0) != (y%2 == 0) && isOpen(x, y):
+				passages++
+				if x == w-1 || y == h-1 {
+					crossings++
+				}
+			}
+		}
+	}
+	if passages != cells-1 {
+		t.Errorf("Expected %d passages for a perfect maze, got %d", cells-1, passages)
+	}
+	return crossings
+}
+
+func TestWrap(t *testing.T) {
+	crossings := map[maze.Wrap]int{}
+	for _, wrap := range []maze.Wrap{maze.WrapHorizontal, maze.WrapVertical, maze.Torus} {
+		for _, algo := range []string{"dfs", "growingtree:random", "huntandkill", "kruskal", "prim", "wilson", "aldousbroder"} {
+			m, err := maze.New(21, 15, 0, 0)
+			if err != nil {
+				t.Fatalf("Failed to create maze: %v", err)
+			}
+			g, err := maze.ParseGenerator(algo)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", algo, err)
+			}
+			if err := m.SetGenerator(g); err != nil {
+				t.Fatalf("Failed to set generator: %v", err)
+			}
+			if err := m.SetWrap(wrap); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if err := m.Generate(3, nil, nil, nil, "", 0.5); err != nil {
+				t.Fatalf("Expected no error with %s, but got %v", algo, err)
+			}
+			crossings[wrap] += checkWrappedMaze(t, m)
+
+			path, found := m.Solve()
+			if !found || path[0] != m.Start() || path[len(path)-1] != m.End() {
+				t.Fatalf("Expected a route from Start to End with %s, got %v", algo, path)
+			}
+			if d := m.PlacementResult().Distance; d != len(path)-1 {
+				t.Errorf("Expected the placement distance to match the route of %d steps, got %d", len(path)-1, d)
+			}
+			// Every step moves to a neighboring point, or from one side of a
+			// joined border to the cell just inside the other.
+			for i := 1; i < len(path); i++ {
+				a, b := path[i-1], path[i]
+				dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+				if dx == m.Width()-2 || dy == m.Height()-2 {
+					dx, dy = min(dx, 1), min(dy, 1)
+				}
+				if c, _ := m.Cell(b.X, b.Y); dx+dy != 1 || c == maze.Wall {
+					t.Fatalf("Expected the route to follow passages, but it steps from %+v to %+v", a, b)
+				}
+			}
+		}
+	}
+	for wrap, n := range crossings {
+		if n == 0 {
+			t.Errorf("Expected passages across the joined borders of wrap %d", wrap)
+		}
+	}
+}
+
+func TestParseWrap(t *testing.T) {
+	for name, want := range map[string]maze.Wrap{"none": maze.NoWrap, "Cylinder": maze.WrapHorizontal, "vertical": maze.WrapVertical, "torus": maze.Torus, "both": maze.Torus} {
+		if w, err := maze.ParseWrap(name); err != nil || w != want {
+			t.Errorf("Expected %q to parse as %d, got %d, %v", name, want, w, err)
+		}
+	}
+	if _, err := maze.ParseWrap("sphere"); err == nil {
+		t.Errorf("Expected an error for an unknown wrap")
+	}
+}
+
+func TestWrapErrors(t *testing.T) {
+	m, err := maze.New(5, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetWrap(maze.WrapHorizontal); err == nil {
+		t.Errorf("Expected an error for a maze too narrow to wrap")
+	}
+	if err := m.SetWrap(maze.WrapVertical); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+
+	m, err = maze.New(21, 21, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create maze: %v", err)
+	}
+	if err := m.SetWrap(maze.Torus); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.SetGenerator(maze.Sidewinder{}); err != nil {
+		t.Fatalf("Failed to set generator: %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil || !strings.Contains(err.Error(), "wrap") || !strings.Contains(err.Error(), "sidewinder") {
+		t.Errorf("Expected sidewinder to be rejected by name for a wrapping maze, got %v", err)
+	}
+
+	if err := m.SetGenerator(nil); err != nil {
+
+		t.Fatalf("Failed to set generator: %v", err)
+
+	}
+	if err := m.SetWrap(maze.WrapHorizontal); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.SetOpenings(&maze.Opening{Side: "left"}, nil); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err == nil {
+		t.Errorf("Expected an error for an entrance on a joined border")
+	}
+	if err := m.SetOpenings(&maze.Opening{}, &maze.Opening{}); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if err := m.Generate(1, nil, nil, nil, "", 0.5); err != nil {
+		t.Fatalf("Expected openings on the borders that are not joined, got %v", err)
+	}
+	for _, p := range []maze.Point{m.Start(), m.End()} {
+		if p.Y != 0 && p.Y != m.Height()-1 {
+			t.Errorf("Expected an opening in the top or bottom border, got %+v", p)
+		}
+	}
+}